@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
@@ -16,14 +18,39 @@ var (
 	commit  = "none"
 	date    = "unknown"
 
+	// Effective configuration (config file, environment, then flags)
+	cfg adr.Config
+
 	// Command flags
-	flagDir         string
-	flagTemplate    string
-	flagStatus      string
-	flagDate        string
-	flagOut         string
-	flagProjectName string
-	flagProjectURL  string
+	flagConfig       string
+	flagDir          string
+	flagTemplate     string
+	flagMarkup       string
+	flagStatus       string
+	flagDate         string
+	flagOut          string
+	flagProjectName  string
+	flagProjectURL   string
+	flagBy           string
+	flagGraph        bool
+	flagFormat       string
+	flagListRules    bool
+	flagCheck        bool
+	flagIndexFormat  string
+	flagColumns      []string
+	flagInject       string
+	flagHistory      bool
+	flagForce        bool
+	flagNoIndex      bool
+	flagSection      string
+	flagListSections bool
+	flagDraft        bool
+	flagBundle       bool
+	flagCategory     string
+	flagSources      []string
+	flagDryRun       bool
+	flagKeep         []string
+	flagCloseGaps    bool
 )
 
 func main() {
@@ -33,13 +60,22 @@ func main() {
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
 	}
 
-	root.PersistentFlags().StringVar(&flagDir, "dir", "ADRs", "ADR directory")
+	root.PersistentFlags().StringVar(&flagConfig, "config", "", "Config file (defaults to "+adr.ConfigFileName+" found by walking up from the working directory)")
+	root.PersistentFlags().StringVar(&flagDir, "dir", "", "ADR directory (defaults to .adr-dir, the config, or a well-known path such as docs/adr, else ADRs at the git root)")
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		cfg = c
+		return nil
+	}
 
 	cmdInit := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize ADR directory",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return adr.EnsureDir(flagDir)
+			return adr.EnsureDir(cfg.Dir)
 		},
 	}
 
@@ -48,9 +84,10 @@ func main() {
 		Short: "Create a new ADR from a template",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			m := adr.Manager{Dir: flagDir}
+			m := adr.NewManager(cfg)
 			title := args[0]
-			opt := adr.NewOptions{Template: flagTemplate, Status: flagStatus, Date: flagDate}
+			opt := adr.NewOptions{Template: cfg.Template, Format: cfg.Format, Status: cfg.Status, Date: flagDate, Draft: flagDraft,
+				Bundle: flagBundle, Category: flagCategory}
 			path, err := m.WriteNewADR(title, opt)
 			if err != nil {
 				return err
@@ -60,35 +97,480 @@ func main() {
 		},
 	}
 	cmdNew.Flags().StringVar(&flagTemplate, "template", "madr", "Template to use: madr|nygard|/path/to/template.md")
+	cmdNew.Flags().StringVar(&flagMarkup, "format", adr.MarkupMarkdown, "File format of the ADR: md|adoc|rst (a template file's extension takes precedence)")
 	cmdNew.Flags().StringVar(&flagStatus, "status", "Proposed", "Initial ADR status")
 	cmdNew.Flags().StringVar(&flagDate, "date", "", "ISO date (YYYY-MM-DD); defaults to today")
+	cmdNew.Flags().BoolVar(&flagDraft, "draft", false, "Write an unnumbered draft to <dir>/drafts; number it later with adrctl promote")
+	cmdNew.Flags().BoolVar(&flagBundle, "bundle", false, "Create the ADR as a directory, <id>-<slug>/README.md, to keep diagrams and other assets next to it")
+	cmdNew.Flags().StringVar(&flagCategory, "category", "", "Subdirectory of the ADR directory to create the ADR in, e.g. platform")
+
+	cmdPromote := &cobra.Command{
+		Use:   "promote <slug>",
+		Short: "Number a draft ADR and move it into the ADR directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := adr.NewManager(cfg).Promote(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			if flagNoIndex {
+				return nil
+			}
+			out, err := refreshIndex()
+			if err != nil {
+				return err
+			}
+			if out != "" {
+				fmt.Println(out)
+			}
+			return nil
+		},
+	}
+	cmdPromote.Flags().BoolVar(&flagNoIndex, "no-index", false, "Do not regenerate the index afterwards")
 
 	cmdIndex := &cobra.Command{
 		Use:   "index",
 		Short: "Generate or update index.md for ADRs",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if flagOut == "" {
-				flagOut = filepath.Join(flagDir, "index.md")
-			}
-			entries, err := adr.Scan(flagDir)
+			out := cfg.IndexOut()
+			entries, sources, err := scanIndex()
 			if err != nil {
 				return err
 			}
-			if err := adr.WriteIndex(flagOut, entries, flagProjectName, flagProjectURL); err != nil {
+			opt := indexOptions()
+			opt.Format = flagIndexFormat
+			// Data formats go to stdout unless --out is given explicitly.
+			if !strings.EqualFold(opt.Format, adr.FormatMarkdown) && !cmd.Flags().Changed("out") {
+				if flagCheck {
+					return fmt.Errorf("--check with --format %s requires --out", opt.Format)
+				}
+				return adr.RenderIndex(os.Stdout, entries, opt)
+			}
+			// --inject (or index.inject) updates the marker regions of an
+			// existing file, unless --out asks for a standalone index.
+			if cfg.Index.Inject != "" && !cmd.Flags().Changed("out") {
+				if !strings.EqualFold(opt.Format, adr.FormatMarkdown) {
+					return fmt.Errorf("--inject cannot be combined with --format %s", opt.Format)
+				}
+				return injectIndex(cmd, cfg.Index.Inject, entries, sources, opt)
+			}
+			setLinkBases(&opt, out, sources)
+			if flagCheck {
+				diff, err := adr.CheckIndex(out, entries, opt)
+				if err != nil {
+					return err
+				}
+				if diff != "" {
+					fmt.Print(diff)
+					cmd.SilenceUsage = true
+					cmd.SilenceErrors = true
+					return fmt.Errorf("%s is out of date; run adrctl index to regenerate it", out)
+				}
+				return nil
+			}
+			if err := adr.WriteIndexOptions(out, entries, opt); err != nil {
 				return err
 			}
-			fmt.Fprintln(os.Stdout, flagOut)
+			fmt.Fprintln(os.Stdout, out)
 			return nil
 		},
 	}
-	cmdIndex.Flags().StringVar(&flagOut, "out", "", "Output index path (defaults to <dir>/index.md)")
+	cmdIndex.Flags().StringVar(&flagOut, "out", "", "Output index path (defaults to index.out from config, else <dir>/index.md)")
 	cmdIndex.Flags().StringVar(&flagProjectName, "project-name", "", "Project name to display in index header")
 	cmdIndex.Flags().StringVar(&flagProjectURL, "project-url", "", "Project URL to link in index header")
+	cmdIndex.Flags().StringVar(&flagTemplate, "template", "", "Custom index template (defaults to index.template from config, else built-in)")
+	cmdIndex.Flags().StringSliceVar(&flagColumns, "columns", nil, "Index table columns, e.g. id,title,status,deciders,date (default id,title,status,date)")
+	cmdIndex.Flags().StringVar(&flagIndexFormat, "format", adr.FormatMarkdown, "Output format: markdown|json|yaml|csv|jsonl")
+	cmdIndex.Flags().BoolVar(&flagCheck, "check", false, "Exit non-zero with a diff if the index is out of date instead of writing it")
+	cmdIndex.Flags().BoolVar(&flagGraph, "graph", false, "Embed a Mermaid decision graph in the index")
+	cmdIndex.Flags().StringVar(&flagInject, "inject", "", "Update the adrctl:index marker regions of an existing markdown file instead of writing index.md")
+	cmdIndex.Flags().StringSliceVar(&flagSources, "source", nil, "ADR directories or glob patterns to combine into one index, optionally named as name=dir (repeatable; defaults to index.sources from config, else --dir)")
+
+	cmdSupersede := &cobra.Command{
+		Use:   "supersede <old-id> [new title]",
+		Short: "Supersede an ADR with a new or existing ADR",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := adr.NewManager(cfg)
+			if flagBy != "" {
+				if len(args) != 1 {
+					return fmt.Errorf("a new title cannot be combined with --by")
+				}
+				return m.SupersedeBy(args[0], flagBy)
+			}
+			if len(args) != 2 {
+				return fmt.Errorf("requires a new ADR title or --by <existing-id>")
+			}
+			opt := adr.NewOptions{Template: cfg.Template, Format: cfg.Format, Status: cfg.Status, Date: flagDate}
+			path, err := m.Supersede(args[0], args[1], opt)
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+	cmdSupersede.Flags().StringVar(&flagBy, "by", "", "Existing ADR that supersedes <old-id>")
+	cmdSupersede.Flags().StringVar(&flagTemplate, "template", "madr", "Template for the new ADR: madr|nygard|/path/to/template.md")
+	cmdSupersede.Flags().StringVar(&flagMarkup, "format", adr.MarkupMarkdown, "File format of the new ADR: md|adoc|rst")
+	cmdSupersede.Flags().StringVar(&flagStatus, "status", "Proposed", "Initial status of the new ADR")
+	cmdSupersede.Flags().StringVar(&flagDate, "date", "", "ISO date (YYYY-MM-DD) of the new ADR; defaults to today")
+
+	cmdStatus := &cobra.Command{
+		Use:   "status <id> <new-status>",
+		Short: "Change the status of an ADR and refresh the index",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := adr.NewManager(cfg)
+			opt := adr.StatusOptions{Date: flagDate, History: flagHistory, Force: flagForce}
+			e, err := m.SetStatus(args[0], args[1], opt)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("ADR %s: %s\n", e.ID, e.Status)
+			if flagNoIndex {
+				return nil
+			}
+			out, err := refreshIndex()
+			if err != nil {
+				return err
+			}
+			if out != "" {
+				fmt.Println(out)
+			}
+			return nil
+		},
+	}
+	cmdStatus.Flags().StringVar(&flagDate, "date", "", "Also set the ADR date (YYYY-MM-DD)")
+	cmdStatus.Flags().BoolVar(&flagHistory, "history", false, "Append the change to status_history in the frontmatter")
+	cmdStatus.Flags().BoolVar(&flagForce, "force", false, "Allow transitions the lifecycle does not permit")
+	cmdStatus.Flags().BoolVar(&flagNoIndex, "no-index", false, "Do not regenerate the index afterwards")
+
+	cmdRenumber := &cobra.Command{
+		Use:   "renumber",
+		Short: "Give ADRs that share a number (e.g. after a merge) new numbers, report gaps, and fix links to them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := adr.NewManager(cfg)
+			plan, err := m.PlanRenumber(adr.RenumberOptions{Keep: flagKeep, CloseGaps: flagCloseGaps})
+			if err != nil {
+				return err
+			}
+			if plan.Empty() {
+				fmt.Print(plan)
+				if len(plan.Gaps) > 0 {
+					fmt.Println("nothing to renumber; use --close-gaps to close the gaps")
+				} else {
+					fmt.Println("nothing to renumber")
+				}
+				return nil
+			}
+			fmt.Print(plan)
+			if flagDryRun {
+				return nil
+			}
+			if err := m.ApplyRenumber(plan); err != nil {
+				return err
+			}
+			if flagNoIndex {
+				return nil
+			}
+			out, err := refreshIndex()
+			if err != nil {
+				return err
+			}
+			if out != "" {
+				fmt.Println(out)
+			}
+			return nil
+		},
+	}
+	cmdRenumber.Flags().BoolVar(&flagDryRun, "dry-run", false, "Print the plan without changing any files")
+	cmdRenumber.Flags().StringSliceVar(&flagKeep, "keep", nil, "ADR file that keeps a shared number (default: the one committed to git first)")
+	cmdRenumber.Flags().BoolVar(&flagCloseGaps, "close-gaps", false, "Also renumber ADRs to close gaps in the sequence")
+	cmdRenumber.Flags().BoolVar(&flagNoIndex, "no-index", false, "Do not regenerate the index afterwards")
+
+	cmdShow := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Print an ADR, one of its sections, or its outline",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := adr.NewManager(cfg).Find(args[0])
+			if err != nil {
+				return err
+			}
+			d, err := adr.LoadDocument(filepath.Join(cfg.Dir, e.File))
+			if err != nil {
+				return err
+			}
+			outline := d.Outline()
+			switch {
+			case flagListSections:
+				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				outline.Walk(func(s *adr.Section) {
+					fmt.Fprintf(w, "%s%s\t%s\n", strings.Repeat("  ", s.Level-1), s.Name, s.Title)
+				})
+				return w.Flush()
+			case flagSection != "":
+				s := outline.Find(flagSection)
+				if s == nil {
+					return fmt.Errorf("ADR %s has no %q section; use --list-sections to see its outline", e.ID, flagSection)
+				}
+				fmt.Println(strings.Trim(s.Text, "\n"))
+			default:
+				fmt.Print(string(d.Bytes()))
+			}
+			return nil
+		},
+	}
+	cmdShow.Flags().StringVar(&flagSection, "section", "", "Print only this section, e.g. context, decision, consequences")
+	cmdShow.Flags().BoolVar(&flagListSections, "list-sections", false, "List the ADR's sections with their canonical names")
+
+	cmdCurrent := &cobra.Command{
+		Use:   "current <id>",
+		Short: "Show the decision currently in effect for an ADR, following supersession",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := adr.NewManager(cfg).Scan()
+			if err != nil {
+				return err
+			}
+			chain, err := adr.Resolve(entries, args[0])
+			if err != nil {
+				return err
+			}
+			cur := chain[len(chain)-1]
+			fmt.Printf("ADR %s: %s (%s)\n", cur.ID, cur.Title, filepath.Join(cfg.Dir, cur.File))
+			if len(chain) > 1 {
+				ids := make([]string, len(chain))
+				for i, e := range chain {
+					ids[i] = e.ID
+				}
+				fmt.Printf("via %s\n", strings.Join(ids, " -> "))
+			}
+			return nil
+		},
+	}
+
+	cmdGraph := &cobra.Command{
+		Use:   "graph",
+		Short: "Print the decision graph as Graphviz DOT or a Mermaid flowchart",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := adr.NewManager(cfg).Scan()
+			if err != nil {
+				return err
+			}
+			return adr.WriteGraph(os.Stdout, entries, flagFormat)
+		},
+	}
+	cmdGraph.Flags().StringVar(&flagFormat, "format", "mermaid", "Output format: dot|mermaid")
 
-	root.AddCommand(cmdInit, cmdNew, cmdIndex)
+	cmdLint := &cobra.Command{
+		Use:   "lint",
+		Short: "Validate ADRs and report problems (exits non-zero on errors)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flagListRules {
+				for _, r := range adr.Rules() {
+					sev := r.Severity
+					if s, ok := cfg.Lint.Rules[r.Name]; ok {
+						sev = s
+					}
+					fmt.Printf("%-20s %-8s %s\n", r.Name, sev, r.Description)
+				}
+				return nil
+			}
+			lintCfg := cfg.Lint
+			lintCfg.Lifecycle = cfg.Lifecycle
+			lintCfg.IDs = cfg.ID
+			if lintCfg.Template == "" {
+				lintCfg.Template = cfg.Template
+			}
+			violations, err := adr.Lint(cfg.Dir, lintCfg)
+			if err != nil {
+				return err
+			}
+			errs, warns := 0, 0
+			for _, v := range violations {
+				fmt.Println(v)
+				if v.Severity == adr.SeverityError {
+					errs++
+				} else {
+					warns++
+				}
+			}
+			if errs > 0 {
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return fmt.Errorf("lint: %d error(s), %d warning(s)", errs, warns)
+			}
+			if warns > 0 {
+				fmt.Fprintf(os.Stderr, "lint: %d warning(s)\n", warns)
+			}
+			return nil
+		},
+	}
+	cmdLint.Flags().BoolVar(&flagListRules, "list-rules", false, "List lint rules and their effective severity")
+
+	root.AddCommand(cmdInit, cmdNew, cmdPromote, cmdIndex, cmdSupersede, cmdStatus, cmdRenumber, cmdShow, cmdCurrent, cmdGraph, cmdLint)
 
 	if err := root.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// loadConfig reads the project config and applies any flags the user set
+// explicitly, so flags take precedence over environment and file settings.
+func loadConfig(cmd *cobra.Command) (adr.Config, error) {
+	var c adr.Config
+	var err error
+	if flagConfig != "" {
+		c, err = adr.LoadConfigFile(flagConfig)
+	} else {
+		var wd string
+		if wd, err = os.Getwd(); err == nil {
+			c, err = adr.LoadConfig(wd)
+		}
+	}
+	if err != nil {
+		return adr.Config{}, err
+	}
+
+	flags := cmd.Flags()
+	targets := map[string]*string{
+		"dir":          &c.Dir,
+		"template":     &c.Template,
+		"status":       &c.Status,
+		"out":          &c.Index.Out,
+		"inject":       &c.Index.Inject,
+		"project-name": &c.Project.Name,
+		"project-url":  &c.Project.URL,
+	}
+	switch cmd.Name() {
+	case "index":
+		// index --template selects the index template, not the ADR template
+		targets["template"] = &c.Index.Template
+	case "new", "supersede":
+		// elsewhere --format is an output format
+		targets["format"] = &c.Format
+	}
+	for name, dst := range targets {
+		if f := flags.Lookup(name); f != nil && f.Changed {
+			*dst = f.Value.String()
+		}
+	}
+	if f := flags.Lookup("graph"); f != nil && f.Changed {
+		c.Index.Graph = flagGraph
+	}
+	if f := flags.Lookup("columns"); f != nil && f.Changed {
+		c.Index.Columns = flagColumns
+	}
+	if f := flags.Lookup("source"); f != nil && f.Changed {
+		c.Index.Sources = flagSources
+	}
+	return c, nil
+}
+
+// indexOptions returns the markdown index settings from the configuration.
+func indexOptions() adr.IndexOptions {
+	return adr.IndexOptions{
+		ProjectName: cfg.Project.Name,
+		ProjectURL:  cfg.Project.URL,
+		Graph:       cfg.Index.Graph,
+		Format:      adr.FormatMarkdown,
+		Columns:     cfg.Index.Columns,
+		Template:    cfg.Index.Template,
+		Regions:     cfg.Index.Regions,
+	}
+}
+
+// scanIndex reads the ADRs shown in the index: the ADR directory, or the
+// configured sources combined.
+func scanIndex() ([]adr.Entry, []adr.Source, error) {
+	var entries []adr.Entry
+	var sources []adr.Source
+	var err error
+	if len(cfg.Index.Sources) == 0 {
+		entries, err = adr.NewManager(cfg).Scan()
+	} else if sources, err = adr.ExpandSources(cfg.Index.Sources); err == nil {
+		entries, err = adr.ScanSources(sources, cfg.ID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	dirs := map[string]string{}
+	for _, s := range sources {
+		dirs[s.Name] = s.Dir
+	}
+	for _, e := range entries {
+		if e.Err != nil {
+			dir := cfg.Dir
+			if d, ok := dirs[e.Source]; ok {
+				dir = d
+			}
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", filepath.Join(dir, e.File), e.Err)
+		}
+	}
+	return entries, sources, nil
+}
+
+// setLinkBases points the index links at the ADR directory and at every
+// source, relative to the output file out.
+func setLinkBases(opt *adr.IndexOptions, out string, sources []adr.Source) {
+	opt.LinkBase = adr.LinkBase(out, cfg.Dir)
+	if len(sources) > 0 {
+		opt.SourceLinks = map[string]string{}
+		for _, s := range sources {
+			opt.SourceLinks[s.Name] = adr.LinkBase(out, s.Dir)
+		}
+	}
+}
+
+// refreshIndex regenerates the configured index after an ADR changed. It
+// only updates an index that already exists and returns its path, or "".
+func refreshIndex() (string, error) {
+	path := cfg.IndexOut()
+	if cfg.Index.Inject != "" {
+		path = cfg.Index.Inject
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", nil
+	}
+	entries, sources, err := scanIndex()
+	if err != nil {
+		return "", err
+	}
+	opt := indexOptions()
+	setLinkBases(&opt, path, sources)
+	if cfg.Index.Inject != "" {
+		return path, adr.WriteIndexInto(path, entries, opt)
+	}
+	return path, adr.WriteIndexOptions(path, entries, opt)
+}
+
+// injectIndex updates (or with --check, verifies) the marker regions of an
+// existing markdown file.
+func injectIndex(cmd *cobra.Command, path string, entries []adr.Entry, sources []adr.Source, opt adr.IndexOptions) error {
+	setLinkBases(&opt, path, sources)
+	if flagCheck {
+		diff, err := adr.CheckIndexInto(path, entries, opt)
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			fmt.Print(diff)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return fmt.Errorf("%s is out of date; run adrctl index to regenerate it", path)
+		}
+		return nil
+	}
+	if err := adr.WriteIndexInto(path, entries, opt); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, path)
+	return nil
+}
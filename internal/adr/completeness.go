@@ -0,0 +1,258 @@
+package adr
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateSentinel replaces the template variables when a template is
+// rendered for comparison, so lines built from them can be recognized.
+const templateSentinel = "ADRCTL-TEMPLATE-VALUE"
+
+// templateModel is what an untouched ADR created from a template looks like:
+// the sections it provides and the placeholder lines under them.
+type templateModel struct {
+	sections     map[string]bool   // canonical section name -> required
+	placeholders map[string]string // trimmed placeholder line -> section name
+}
+
+// reOptional marks a template section that may be left empty: a comment
+// reading "optional" after a markdown heading or on a line of the section,
+// e.g. "## Links <!-- optional -->", "// optional" in AsciiDoc or
+// ".. optional" in reStructuredText.
+var reOptional = regexp.MustCompile(`(?i)^(?:<!--\s*optional\s*-->|//\s*optional|\.\.\s+optional)$`)
+
+// loadTemplateModel renders an ADR template in the given format with
+// sentinel values and collects its sections, whether each must be filled
+// in, and its placeholder text.
+func loadTemplateModel(name, markup string) (*templateModel, error) {
+	tpl, err := Manager{}.loadTemplate(name, markup)
+	if err != nil {
+		return nil, fmt.Errorf("lint template: %w", err)
+	}
+	var buf bytes.Buffer
+	data := map[string]any{"ID": templateSentinel, "Title": templateSentinel, "Status": templateSentinel, "Date": templateSentinel}
+	if err := tpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("lint template: %w", err)
+	}
+	d, err := parseDocument(buf.Bytes(), markup)
+	if err != nil {
+		return nil, fmt.Errorf("lint template: %w", err)
+	}
+
+	model := &templateModel{sections: map[string]bool{}, placeholders: map[string]string{}}
+	d.Outline().Walk(func(s *Section) {
+		if s.Name == "title" {
+			return
+		}
+		required := !strings.Contains(strings.ToLower(string(d.content[s.head:s.start])), "<!-- optional -->")
+		for _, line := range strings.Split(s.Content, "\n") {
+			line = strings.TrimSpace(line)
+			if reOptional.MatchString(line) {
+				required = false
+			}
+			if isContentLine(line) && !strings.Contains(strings.ToUpper(line), templateSentinel) {
+				model.placeholders[line] = s.Name
+			}
+		}
+		model.sections[s.Name] = required
+	})
+	return model, nil
+}
+
+// templateModels holds the templates ADRs may have been created from,
+// each rendered once per lint run and format.
+type templateModels struct {
+	names  []string // candidate templates; the first is the default
+	models map[[2]string]*templateModel
+}
+
+// newTemplateModels loads the configured template, the other configured
+// templates and the built-in ones, failing on any that cannot be read.
+func newTemplateModels(cfg LintConfig) (*templateModels, error) {
+	t := &templateModels{models: map[[2]string]*templateModel{}}
+	seen := map[string]bool{}
+	for _, name := range append(append([]string{cfg.Template}, cfg.Templates...), "madr", "nygard") {
+		if key := strings.ToLower(strings.TrimSpace(name)); key == "" || key == "madr" {
+			name = "madr"
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, err := t.get(name, MarkupMarkdown); err != nil {
+			return nil, err
+		}
+		t.names = append(t.names, name)
+	}
+	return t, nil
+}
+
+// get returns the model of a template rendered for ADRs in the given
+// format. Template files are read in their own format.
+func (t *templateModels) get(name, markup string) (*templateModel, error) {
+	if m, ok := templateMarkup(name); ok {
+		markup = m
+	}
+	key := [2]string{name, markup}
+	if model, ok := t.models[key]; ok {
+		return model, nil
+	}
+	model, err := loadTemplateModel(name, markup)
+	if err != nil {
+		return nil, err
+	}
+	t.models[key] = model
+	return model, nil
+}
+
+// match returns the model of the template f was most likely created from:
+// the one sharing the most sections and unedited placeholder lines with
+// it. Ties go to the template listed first.
+func (t *templateModels) match(f *lintFile) *templateModel {
+	var best *templateModel
+	bestScore := -1
+	for _, name := range t.names {
+		model, err := t.get(name, f.Markup)
+		if err != nil {
+			continue // no template in this format
+		}
+		score := 0
+		f.Doc.Outline().Walk(func(s *Section) {
+			if _, ok := model.sections[s.Name]; ok {
+				score++
+			}
+		})
+		for _, line := range f.Lines {
+			if _, ok := model.placeholders[strings.TrimSpace(line)]; ok {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = model, score
+		}
+	}
+	return best
+}
+
+// isContentLine reports whether a trimmed line carries text, as opposed to
+// blank lines, bare list markers, headings, bold labels, comments and
+// optional-section markers.
+func isContentLine(line string) bool {
+	if !strings.ContainsFunc(line, func(r rune) bool {
+		return r > 127 || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+	}) {
+		return false
+	}
+	if reHeading.MatchString(line) || reOptional.MatchString(line) || (strings.HasPrefix(line, "<!--") && strings.HasSuffix(line, "-->")) {
+		return false
+	}
+	if _, n, ok := parseLabel(line); ok && strings.TrimSpace(line[n:]) == "" {
+		return false
+	}
+	return true
+}
+
+// strictStatus reports whether ADRs with the given status have their
+// completeness warnings reported as errors.
+func strictStatus(cfg LintConfig, status string) bool {
+	strict := cfg.Strict
+	if len(strict) == 0 {
+		strict = []string{"Accepted"}
+	}
+	name, ok := cfg.Lifecycle.Normalize(status)
+	if !ok {
+		return false
+	}
+	for _, s := range strict {
+		if n, _ := cfg.Lifecycle.Normalize(s); n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkPlaceholderText(files []*lintFile, _ LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		if f.template == nil {
+			continue
+		}
+		first := bytes.Count(f.Doc.content[:f.Doc.body], []byte("\n"))
+		for i := first; i < len(f.Lines); i++ {
+			line := strings.TrimSpace(f.Lines[i])
+			if section, ok := f.template.placeholders[line]; ok {
+				out = append(out, Violation{File: f.Path, Line: i + 1, strict: f.strict,
+					Message: fmt.Sprintf("placeholder %q in %s was never replaced", line, section)})
+			}
+		}
+	}
+	return out
+}
+
+// checkEmptySection reports required sections without content: those
+// listed in lint.sections, else the sections of the ADR's template not
+// marked optional.
+func checkEmptySection(files []*lintFile, cfg LintConfig) []Violation {
+	configured := map[string]bool{}
+	for _, name := range cfg.Sections {
+		configured[SectionName(name)] = true
+	}
+	var out []Violation
+	for _, f := range files {
+		if f.template == nil {
+			continue
+		}
+		required := configured
+		if len(required) == 0 {
+			required = f.template.sections
+		}
+		f.Doc.Outline().Walk(func(s *Section) {
+			if s.Name == "title" || !required[s.Name] {
+				return
+			}
+			if sectionIsEmpty(s) {
+				out = append(out, Violation{File: f.Path, Line: s.Line, strict: f.strict,
+					Message: fmt.Sprintf("section %q is empty", s.Title)})
+			}
+		})
+	}
+	return out
+}
+
+// sectionIsEmpty reports whether a section has no text at all. Placeholder
+// text counts as content here, since placeholder-text reports it. Text in
+// subsections counts as content too, but their headings do not.
+func sectionIsEmpty(s *Section) bool {
+	for _, line := range strings.Split(s.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if isContentLine(line) {
+			return false
+		}
+	}
+	for _, c := range s.Children {
+		if !sectionIsEmpty(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkMissingSection(files []*lintFile, cfg LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		if f.Doc == nil || len(cfg.Sections) == 0 {
+			continue
+		}
+		outline := f.Doc.Outline()
+		for _, name := range cfg.Sections {
+			if outline.Find(name) == nil {
+				out = append(out, Violation{File: f.Path, Line: 1,
+					Message: fmt.Sprintf("required section %q is missing", SectionName(name))})
+			}
+		}
+	}
+	return out
+}
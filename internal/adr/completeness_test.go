@@ -0,0 +1,166 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLintCompleteness verifies placeholders and empty sections are found
+// by comparing ADRs with their template, and that they become errors once
+// an ADR is accepted.
+func TestLintCompleteness(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+	template := "../../examples/templates/openchami.md"
+	for _, status := range []string{"Proposed", "Accepted"} {
+		path, err := m.WriteNewADR("Use Go "+status, NewOptions{Template: template, Status: status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := os.ReadFile(path)
+		filled := strings.Replace(string(content), "[Background and context leading to the decision.]", "We need a language.", 1)
+		filled = strings.Replace(filled, "[The decision that has been made.]", "", 1)
+		if err := os.WriteFile(path, []byte(filled), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := LintConfig{Template: template, Rules: map[string]Severity{"missing-frontmatter": SeverityOff}}
+	violations, err := Lint(dir, cfg)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	got := map[string]Severity{}
+	for _, v := range violations {
+		got[filepath.Base(v.File)+":"+v.Rule+":"+v.Message] = v.Severity
+	}
+	want := map[string]Severity{
+		`0001-use-go-proposed.md:placeholder-text:placeholder "[Implications of the decision.]" in consequences was never replaced`: SeverityWarning,
+		`0001-use-go-proposed.md:empty-section:section "Decision" is empty`:                                                         SeverityWarning,
+		`0002-use-go-accepted.md:placeholder-text:placeholder "[Implications of the decision.]" in consequences was never replaced`: SeverityError,
+		`0002-use-go-accepted.md:empty-section:section "Decision" is empty`:                                                         SeverityError,
+	}
+	for k, sev := range want {
+		if got[k] != sev {
+			t.Errorf("%s: severity %q, want %q", k, got[k], sev)
+		}
+	}
+	for k := range got {
+		if strings.Contains(k, "Background") || strings.Contains(k, `"Context" is empty`) {
+			t.Errorf("edited section reported: %s", k)
+		}
+	}
+}
+
+// TestLintEmptySections verifies empty required MADR sections are reported
+// unless they, or their subsections, have content. Subsection headings
+// alone are not content, and optional sections such as Links may be empty.
+func TestLintEmptySections(t *testing.T) {
+	dir := t.TempDir()
+	adr := "---\nid: 1\ntitle: \"X\"\nstatus: \"Proposed\"\ndate: \"2025-01-15\"\n---\n\n# ADR 0001: X\n\n" +
+		"## Context and Problem Statement\nWhy.\n\n## Decision Drivers\n\n<!-- none yet -->\n\n" +
+		"## Decision Outcome\n\n### Positive Consequences\nFaster.\n\n" +
+		"## Pros and Cons of the Options\n\n### Option A\n\n### Option B\n\n## Links\n"
+	if err := os.WriteFile(filepath.Join(dir, "0001-x.md"), []byte(adr), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sections []string
+		want     []string
+	}{
+		{
+			nil,
+			[]string{
+				`empty-section: section "Decision Drivers" is empty`,
+				`empty-section: section "Pros and Cons of the Options" is empty`,
+			},
+		},
+		{
+			[]string{"context", "decision-drivers", "pros-and-cons", "consequences-negative"},
+			[]string{
+				`missing-section: required section "consequences-negative" is missing`,
+				`empty-section: section "Decision Drivers" is empty`,
+				`empty-section: section "Pros and Cons of the Options" is empty`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		violations, err := Lint(dir, LintConfig{Sections: tt.sections})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, v := range violations {
+			got = append(got, v.Rule+": "+v.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("sections %v: violations:\n%s\nwant:\n%s", tt.sections, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+
+	if _, err := Lint(dir, LintConfig{Template: filepath.Join(dir, "missing.md")}); err == nil {
+		t.Error("a missing lint template should be an error")
+	}
+}
+
+// TestLintTemplateMatch verifies each ADR is compared with the template it
+// was created from rather than only the configured one.
+func TestLintTemplateMatch(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+	openchami := "../../examples/templates/openchami.md"
+	for _, adr := range [][2]string{{"Use Nygard", "nygard"}, {"Use OpenCHAMI", openchami}} {
+		if _, err := m.WriteNewADR(adr[0], NewOptions{Template: adr[1]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := LintConfig{Template: "nygard", Templates: []string{openchami}}
+	violations, err := Lint(dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, v := range violations {
+		got[filepath.Base(v.File)+": "+v.Message] = true
+	}
+	for _, want := range []string{
+		`0001-use-nygard.md: section "Context" is empty`,
+		`0002-use-openchami.md: placeholder "[Background and context leading to the decision.]" in context was never replaced`,
+	} {
+		if !got[want] {
+			t.Errorf("missing violation %s", want)
+		}
+	}
+}
+
+// TestLintOptionalSections verifies every section of a new MADR ADR is
+// required except those the template marks optional, in every format.
+func TestLintOptionalSections(t *testing.T) {
+	for _, format := range []string{"md", "adoc", "rst"} {
+		dir := t.TempDir()
+		m := Manager{Dir: dir}
+		if _, err := m.WriteNewADR("Use Go", NewOptions{Format: format}); err != nil {
+			t.Fatal(err)
+		}
+		violations, err := Lint(dir, LintConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]bool{}
+		for _, v := range violations {
+			got[v.Message] = true
+		}
+		for _, title := range []string{"Context and Problem Statement", "Decision Drivers", "Considered Options", "Pros and Cons of the Options"} {
+			if !got[`section "`+title+`" is empty`] {
+				t.Errorf("%s: empty %s not reported; got %v", format, title, got)
+			}
+		}
+		if got[`section "Links" is empty`] {
+			t.Errorf("%s: optional Links section reported", format)
+		}
+	}
+}
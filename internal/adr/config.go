@@ -0,0 +1,299 @@
+package adr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the project configuration file adrctl looks for by
+// walking up from the working directory.
+const ConfigFileName = ".adrctl.yaml"
+
+// Config holds project-level settings. Values are layered: built-in defaults,
+// then the config file, then ADRCTL_* environment variables. Command-line
+// flags are applied on top by the CLI.
+type Config struct {
+	Dir      string        `yaml:"dir"`      // ADR directory
+	Template string        `yaml:"template"` // default template for new ADRs
+	Format   string        `yaml:"format"`   // file format of new ADRs: md, adoc or rst
+	Status   string        `yaml:"status"`   // default status for new ADRs
+	Index    IndexConfig   `yaml:"index"`
+	Project  ProjectConfig `yaml:"project"`
+	Lint     LintConfig    `yaml:"lint"`
+	// Lifecycle declares the allowed statuses and transitions; empty means
+	// DefaultLifecycle.
+	Lifecycle Lifecycle `yaml:"lifecycle"`
+	// ID is the ID scheme of new ADRs, also used to recognize ADR files.
+	ID IDScheme `yaml:"id"`
+	// Slug controls how titles become file names.
+	Slug SlugConfig `yaml:"slug"`
+
+	// Path is the config file the settings were read from, if any.
+	Path string `yaml:"-"`
+}
+
+// IndexConfig controls index generation.
+type IndexConfig struct {
+	Out      string   `yaml:"out"`      // output path; defaults to <dir>/index.md
+	Graph    bool     `yaml:"graph"`    // embed a Mermaid decision graph
+	Columns  []string `yaml:"columns"`  // table columns, e.g. [id, title, status, deciders, date]
+	Template string   `yaml:"template"` // custom index template path
+	// Inject writes the table between adrctl:index markers in this markdown
+	// file instead of generating a standalone index.
+	Inject  string                 `yaml:"inject"`
+	Regions map[string]IndexRegion `yaml:"regions"` // named marker regions
+	// Sources are the ADR directories or glob patterns combined into the
+	// index, each optionally named as name=dir; empty means just Dir. See
+	// ExpandSources.
+	Sources []string `yaml:"sources"`
+}
+
+// IndexRegion selects the ADRs and columns shown in a named marker region.
+type IndexRegion struct {
+	Status  []string `yaml:"status"`  // statuses to include; empty means all
+	Columns []string `yaml:"columns"` // table columns; defaults to index.columns
+}
+
+// ProjectConfig holds project metadata shown in the index header.
+type ProjectConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// DefaultConfig returns the settings used when no config file is present.
+func DefaultConfig() Config {
+	return Config{
+		Dir:      "ADRs",
+		Template: "madr",
+		Status:   "Proposed",
+	}
+}
+
+// IndexOut returns the index output path, defaulting to <dir>/index.md.
+func (c Config) IndexOut() string {
+	if c.Index.Out != "" {
+		return c.Index.Out
+	}
+	return filepath.Join(c.Dir, "index.md")
+}
+
+// FindConfig walks up from start looking for ConfigFileName. It returns an
+// empty path and no error when no config file exists.
+func FindConfig(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		fi, err := os.Stat(path)
+		if err == nil && !fi.IsDir() {
+			return path, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig discovers the config file from start (usually the working
+// directory) and returns the effective settings. Relative paths in the file
+// are resolved against the file's directory and returned relative to start.
+func LoadConfig(start string) (Config, error) {
+	path, err := FindConfig(start)
+	if err != nil {
+		return Config{}, err
+	}
+	if path == "" {
+		cfg := DefaultConfig()
+		cfg.Dir = DetectDir(start, "")
+		cfg.applyEnv(os.LookupEnv)
+		return cfg, nil
+	}
+	return loadConfigFile(path, start)
+}
+
+// LoadConfigFile reads settings from an explicit config file path.
+func LoadConfigFile(path string) (Config, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return Config{}, err
+	}
+	return loadConfigFile(path, wd)
+}
+
+func loadConfigFile(path, start string) (Config, error) {
+	cfg := DefaultConfig()
+	cfg.Dir = "" // detected below unless the file sets it
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) { // empty file keeps defaults
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Path = path
+	if err := cfg.Lifecycle.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.ID.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Slug.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	base := filepath.Dir(path)
+	cfg.Dir = DetectDir(start, resolveConfigPath(base, start, cfg.Dir))
+	cfg.Index.Out = resolveConfigPath(base, start, cfg.Index.Out)
+	cfg.Index.Template = resolveConfigPath(base, start, cfg.Index.Template)
+	cfg.Index.Inject = resolveConfigPath(base, start, cfg.Index.Inject)
+	for i, src := range cfg.Index.Sources {
+		if name, dir, ok := strings.Cut(src, "="); ok {
+			cfg.Index.Sources[i] = name + "=" + resolveConfigPath(base, start, dir)
+		} else {
+			cfg.Index.Sources[i] = resolveConfigPath(base, start, src)
+		}
+	}
+	if !isBuiltinTemplate(cfg.Template) {
+		cfg.Template = resolveConfigPath(base, start, cfg.Template)
+	}
+	if !isBuiltinTemplate(cfg.Lint.Template) {
+		cfg.Lint.Template = resolveConfigPath(base, start, cfg.Lint.Template)
+	}
+	for i, name := range cfg.Lint.Templates {
+		if !isBuiltinTemplate(name) {
+			cfg.Lint.Templates[i] = resolveConfigPath(base, start, name)
+		}
+	}
+
+	cfg.applyEnv(os.LookupEnv)
+	return cfg, nil
+}
+
+// applyEnv overrides settings from ADRCTL_* environment variables.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) {
+	for name, dst := range map[string]*string{
+		"ADRCTL_DIR":            &c.Dir,
+		"ADRCTL_TEMPLATE":       &c.Template,
+		"ADRCTL_FORMAT":         &c.Format,
+		"ADRCTL_STATUS":         &c.Status,
+		"ADRCTL_INDEX_OUT":      &c.Index.Out,
+		"ADRCTL_INDEX_TEMPLATE": &c.Index.Template,
+		"ADRCTL_INDEX_INJECT":   &c.Index.Inject,
+		"ADRCTL_PROJECT_NAME":   &c.Project.Name,
+		"ADRCTL_PROJECT_URL":    &c.Project.URL,
+	} {
+		if v, ok := lookup(name); ok && v != "" {
+			*dst = v
+		}
+	}
+}
+
+// ADRDirFile records the ADR directory, relative to the file's own
+// directory, as npryce/adr-tools does.
+const ADRDirFile = ".adr-dir"
+
+// WellKnownDirs are the ADR directories looked for when neither an
+// ADRDirFile nor the config names one.
+var WellKnownDirs = []string{
+	"ADRs",
+	"doc/adr",
+	"docs/adr",
+	"docs/adrs",
+	"docs/decisions",
+	"doc/architecture/decisions",
+	"docs/architecture/decisions",
+}
+
+// DetectDir finds the ADR directory for commands run in start. Walking up
+// from start to the root of the git repository, the first ADRDirFile wins;
+// then configured (the dir setting of the config file, if any); then the
+// first of WellKnownDirs that exists. Failing all of those it is ADRs at
+// the repository root. Outside a git repository only start itself is
+// searched. The result is relative to start.
+func DetectDir(start, configured string) string {
+	abs, err := filepath.Abs(start)
+	if err != nil {
+		if configured != "" {
+			return configured
+		}
+		return DefaultConfig().Dir
+	}
+	levels := searchLevels(abs)
+	for _, dir := range levels {
+		content, err := os.ReadFile(filepath.Join(dir, ADRDirFile))
+		if err != nil {
+			continue
+		}
+		if p := strings.TrimSpace(string(content)); p != "" {
+			return resolveConfigPath(dir, start, filepath.FromSlash(p))
+		}
+	}
+	if configured != "" {
+		return configured
+	}
+	for _, dir := range levels {
+		for _, known := range WellKnownDirs {
+			known = filepath.FromSlash(known)
+			if fi, err := os.Stat(filepath.Join(dir, known)); err == nil && fi.IsDir() {
+				return resolveConfigPath(dir, start, known)
+			}
+		}
+	}
+	return resolveConfigPath(levels[len(levels)-1], start, DefaultConfig().Dir)
+}
+
+// searchLevels returns dir and its parents up to the root of the git
+// repository containing it, or just dir outside a repository.
+func searchLevels(dir string) []string {
+	levels := []string{dir}
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return levels
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return []string{dir}
+		}
+		d = parent
+		levels = append(levels, d)
+	}
+}
+
+// resolveConfigPath interprets p relative to the config file directory and
+// rewrites it relative to start so printed paths stay short.
+func resolveConfigPath(base, start, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	abs := filepath.Join(base, p)
+	if startAbs, err := filepath.Abs(start); err == nil {
+		if rel, err := filepath.Rel(startAbs, abs); err == nil {
+			return rel
+		}
+	}
+	return abs
+}
+
+func isBuiltinTemplate(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "madr", "nygard":
+		return true
+	}
+	return false
+}
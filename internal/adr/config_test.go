@@ -0,0 +1,178 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadConfigWalksUp verifies that .adrctl.yaml is discovered from a nested
+// working directory and that relative paths resolve against the config file.
+func TestLoadConfigWalksUp(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := `dir: docs/adr
+template: nygard
+status: Accepted
+index:
+  out: docs/adr/README.md
+project:
+  name: Example
+  url: https://example.com
+`
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(nested)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if want := filepath.Join("..", "..", "docs", "adr"); cfg.Dir != want {
+		t.Errorf("Dir: got %q, want %q", cfg.Dir, want)
+	}
+	if want := filepath.Join("..", "..", "docs", "adr", "README.md"); cfg.IndexOut() != want {
+		t.Errorf("IndexOut: got %q, want %q", cfg.IndexOut(), want)
+	}
+	if cfg.Template != "nygard" {
+		t.Errorf("Template: got %q, want nygard", cfg.Template)
+	}
+	if cfg.Status != "Accepted" {
+		t.Errorf("Status: got %q, want Accepted", cfg.Status)
+	}
+	if cfg.Project.Name != "Example" || cfg.Project.URL != "https://example.com" {
+		t.Errorf("Project: got %+v", cfg.Project)
+	}
+	if cfg.Path != filepath.Join(root, ConfigFileName) {
+		t.Errorf("Path: got %q", cfg.Path)
+	}
+}
+
+// TestLoadConfigDefaults verifies the built-in defaults when no file exists.
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := LoadConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Dir != "ADRs" || cfg.Template != "madr" || cfg.Status != "Proposed" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if cfg.IndexOut() != filepath.Join("ADRs", "index.md") {
+		t.Errorf("IndexOut: got %q", cfg.IndexOut())
+	}
+}
+
+// TestLoadConfigEnvOverrides verifies ADRCTL_* variables take precedence
+// over the config file.
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte("dir: decisions\nstatus: Accepted\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ADRCTL_STATUS", "Draft")
+	t.Setenv("ADRCTL_PROJECT_NAME", "From Env")
+
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Dir != "decisions" {
+		t.Errorf("Dir: got %q, want decisions", cfg.Dir)
+	}
+	if cfg.Status != "Draft" {
+		t.Errorf("Status: got %q, want Draft", cfg.Status)
+	}
+	if cfg.Project.Name != "From Env" {
+		t.Errorf("Project.Name: got %q, want From Env", cfg.Project.Name)
+	}
+}
+
+// TestLoadConfigUnknownField ensures typos in the config file are reported.
+func TestLoadConfigUnknownField(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte("directory: ADRs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfig(root)
+	if err == nil || !strings.Contains(err.Error(), "directory") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+// TestNewManagerUsesConfigDefaults verifies WriteNewADR falls back to the
+// template and status from the config.
+func TestNewManagerUsesConfigDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Dir = t.TempDir()
+	cfg.Template = "nygard"
+	cfg.Status = "Accepted"
+
+	path, err := NewManager(cfg).WriteNewADR("Config Defaults", NewOptions{Date: "2025-01-15"})
+	if err != nil {
+		t.Fatalf("WriteNewADR failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "## Consequences") {
+		t.Error("expected Nygard template from config")
+	}
+	meta, err := ParseADR(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Status != "Accepted" {
+		t.Errorf("Status: got %q, want Accepted", meta.Status)
+	}
+}
+
+// TestDetectDir verifies the order in which the ADR directory is found:
+// .adr-dir, then the config, then well-known paths, then ADRs at the git
+// root, searching from a subdirectory up to the repository root.
+func TestDetectDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		git        bool
+		dirs       []string
+		adrDir     string // content of .adr-dir at the root
+		configured string
+		want       string // relative to services/api
+	}{
+		{name: "default at git root", git: true, want: "../../ADRs"},
+		{name: "well-known", git: true, dirs: []string{"docs/adr"}, want: "../../docs/adr"},
+		{name: "adr-tools layout", git: true, dirs: []string{"doc/architecture/decisions"}, want: "../../doc/architecture/decisions"},
+		{name: "config beats well-known", git: true, dirs: []string{"docs/adr"}, configured: "../../decisions", want: "../../decisions"},
+		{name: "adr-dir beats config", git: true, adrDir: "doc/arch\n", configured: "../../decisions", want: "../../doc/arch"},
+		{name: "outside git", dirs: []string{"docs/adr"}, want: "ADRs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			start := filepath.Join(root, "services", "api")
+			dirs := append([]string{"services/api"}, tt.dirs...)
+			if tt.git {
+				dirs = append(dirs, ".git")
+			}
+			for _, d := range dirs {
+				if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(d)), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.adrDir != "" {
+				if err := os.WriteFile(filepath.Join(root, ADRDirFile), []byte(tt.adrDir), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := DetectDir(start, filepath.FromSlash(tt.configured)); got != filepath.FromSlash(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+package adr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an ADR file that can be edited without disturbing anything
+// but the edited parts. Frontmatter fields are located through yaml.Node
+// positions and replaced in the original bytes, so key order, comments,
+// quoting and the markdown body are preserved byte for byte. In AsciiDoc
+// and reStructuredText ADRs the attribute entries or field list play the
+// part of the frontmatter.
+type Document struct {
+	Path string
+
+	content []byte
+	markup  string     // MarkupMarkdown, MarkupAsciiDoc or MarkupRST
+	fm      *yaml.Node // frontmatter mapping; nil without frontmatter
+	fmStart int        // offset of the first frontmatter line
+	fmEnd   int        // offset of the closing --- line, or past the last field
+	body    int        // offset of the first body byte
+	format  string     // FrontmatterYAML, FrontmatterTOML, FrontmatterAttributes or FrontmatterFields
+	newline string     // line ending used for inserted lines
+}
+
+// LoadDocument reads and parses the ADR at path, in the format given by its
+// extension.
+func LoadDocument(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	markup, _ := markupOf(path)
+	d, err := parseDocument(content, markup)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	d.Path = path
+	return d, nil
+}
+
+// ParseDocument parses markdown ADR content. It fails only when the
+// frontmatter is not valid YAML or not a mapping.
+func ParseDocument(content []byte) (*Document, error) {
+	return parseDocument(content, MarkupMarkdown)
+}
+
+func parseDocument(content []byte, markup string) (*Document, error) {
+	d := &Document{content: content, markup: markup}
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Save writes the document back to Path if it changed.
+func (d *Document) Save() error {
+	if d.Path == "" {
+		return fmt.Errorf("document has no path")
+	}
+	_, err := writeFileIfChanged(d.Path, d.content)
+	return err
+}
+
+// Bytes returns the current content of the document.
+func (d *Document) Bytes() []byte { return d.content }
+
+// HasFrontmatter reports whether the document has a frontmatter block.
+func (d *Document) HasFrontmatter() bool { return d.fm != nil }
+
+func (d *Document) parse() error {
+	d.fm, d.fmStart, d.fmEnd, d.body = nil, 0, 0, 0
+	d.format, d.newline = FrontmatterYAML, "\n"
+	switch d.markup {
+	case MarkupAsciiDoc:
+		d.format = FrontmatterAttributes
+	case MarkupRST:
+		d.format = FrontmatterFields
+	}
+	b, ok, err := locateMetadata(d.content, d.markup)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if bytes.Contains(d.content, []byte("\r\n")) {
+			d.newline = "\r\n"
+		}
+		return nil
+	}
+	fm, err := decodeFrontmatter(d.content, b)
+	if err != nil {
+		return err
+	}
+	d.fm, d.fmStart, d.fmEnd, d.body = fm, b.start, b.end, b.body
+	d.format, d.newline = b.format, b.newline
+	return nil
+}
+
+// Keys returns the frontmatter keys in file order.
+func (d *Document) Keys() []string {
+	if d.fm == nil {
+		return nil
+	}
+	var keys []string
+	for i := 0; i+1 < len(d.fm.Content); i += 2 {
+		keys = append(keys, d.fm.Content[i].Value)
+	}
+	return keys
+}
+
+// Node returns the value node of a frontmatter key, or nil.
+func (d *Document) Node(key string) *yaml.Node {
+	if i := d.keyIndex(key); i >= 0 {
+		return d.fm.Content[i+1]
+	}
+	return nil
+}
+
+// Get returns the value of a frontmatter key as plain Go values (see
+// Fields.Get) and whether the key is present.
+func (d *Document) Get(key string) (any, bool) {
+	n := d.Node(key)
+	if n == nil {
+		return nil, false
+	}
+	return nodeValue(n), true
+}
+
+// GetString returns a frontmatter value as flat text, or "".
+func (d *Document) GetString(key string) string {
+	v, _ := d.Get(key)
+	return FormatValue(v, ", ")
+}
+
+// Set sets a frontmatter key. An existing key is replaced where it stands,
+// keeping the quoting style of a scalar and the flow or block style of a
+// list; a new key is appended to the frontmatter, which is created if the
+// document has none. value may be a *yaml.Node, a string, an IDList or
+// []string, or anything yaml.v3 can encode.
+func (d *Document) Set(key string, value any) error {
+	n, err := valueNode(value, d.Node(key))
+	if err != nil {
+		return fmt.Errorf("frontmatter %s: %w", key, err)
+	}
+	return d.SetNode(key, n)
+}
+
+// SetNode sets a frontmatter key to a YAML node.
+func (d *Document) SetNode(key string, n *yaml.Node) error {
+	i := d.keyIndex(key)
+	var tail string // bytes kept after a one-line value: spacing and comment
+	if old := d.Node(key); old != nil && n.LineComment == "" {
+		if tail = d.commentTail(i); tail == "" {
+			n.LineComment = old.LineComment
+		}
+	}
+	render := renderEntry
+	switch d.format {
+	case FrontmatterTOML:
+		render = renderTOMLEntry
+	case FrontmatterAttributes, FrontmatterFields:
+		render = func(key string, n *yaml.Node) (string, error) {
+			if i >= 0 {
+				// keep the key as written, e.g. :Status:
+				from, _ := d.entrySpan(i)
+				key = fieldName(d.content[from:])
+			}
+			return renderField(d.format, key, n)
+		}
+	}
+	entry, err := render(key, n)
+	if err != nil {
+		return fmt.Errorf("frontmatter %s: %w", key, err)
+	}
+	if first, rest, _ := strings.Cut(entry, "\n"); tail != "" && rest == "" {
+		entry = first + tail + "\n"
+	} else if tail != "" {
+		// the value now takes several lines; write the comment afresh
+		n.LineComment = d.fm.Content[i+1].LineComment
+		if entry, err = render(key, n); err != nil {
+			return fmt.Errorf("frontmatter %s: %w", key, err)
+		}
+	}
+	entry = strings.ReplaceAll(entry, "\n", d.newline)
+
+	var out []byte
+	switch {
+	case d.fm == nil && (d.format == FrontmatterAttributes || d.format == FrontmatterFields):
+		at, blank := fieldsInsertAt(d.content, d.markup)
+		if blank {
+			entry += d.newline
+		}
+		out = splice(d.content, at, at, d.lineBreakAt(at)+entry)
+	case d.fm == nil:
+		nl := d.newline
+		content := d.content
+		bom := bytes.HasPrefix(content, utf8BOM)
+		if bom {
+			content = content[len(utf8BOM):]
+		}
+		out = append([]byte("---"+nl+entry+"---"+nl+nl), content...)
+		if bom {
+			out = append(append([]byte(nil), utf8BOM...), out...)
+		}
+	case i < 0:
+		at := d.fmEnd
+		if d.format == FrontmatterTOML {
+			at = d.tomlTopEnd()
+		}
+		out = splice(d.content, at, at, d.lineBreakAt(at)+entry)
+	default:
+		from, to := d.entrySpan(i)
+		out = splice(d.content, from, to, entry)
+	}
+	return d.replace(out)
+}
+
+// Delete removes a frontmatter key and reports whether it was present.
+func (d *Document) Delete(key string) (bool, error) {
+	i := d.keyIndex(key)
+	if i < 0 {
+		return false, nil
+	}
+	from, to := d.entrySpan(i)
+	return true, d.replace(splice(d.content, from, to, ""))
+}
+
+// Body returns the markdown after the frontmatter. The body of an AsciiDoc
+// or reStructuredText ADR is the whole document.
+func (d *Document) Body() []byte { return d.content[d.body:] }
+
+// SetBody replaces the markdown after the frontmatter.
+func (d *Document) SetBody(body []byte) error {
+	return d.replace(splice(d.content, d.body, len(d.content), string(body)))
+}
+
+// replace swaps in new content and re-parses it, keeping the old state if
+// the edit produced invalid frontmatter.
+func (d *Document) replace(content []byte) error {
+	old := *d
+	d.content = content
+	if err := d.parse(); err != nil {
+		*d = old
+		return fmt.Errorf("edit produced invalid frontmatter: %w", err)
+	}
+	return nil
+}
+
+func (d *Document) keyIndex(key string) int {
+	if d.fm == nil {
+		return -1
+	}
+	for i := 0; i+1 < len(d.fm.Content); i += 2 {
+		if d.fm.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// entrySpan returns the byte range of the frontmatter entry whose key is
+// d.fm.Content[i]: from the start of the key line up to the next key, less
+// any blank or comment lines that precede it.
+func (d *Document) entrySpan(i int) (int, int) {
+	lines := lineOffsets(d.content, d.fmStart, d.fmEnd)
+	lineStart := func(n int) int { // n is 1-based within the frontmatter
+		if n-1 < len(lines) {
+			return lines[n-1]
+		}
+		return d.fmEnd
+	}
+	from := lineStart(d.fm.Content[i].Line)
+	to := d.fmEnd
+	if i+2 < len(d.fm.Content) {
+		to = lineStart(d.fm.Content[i+2].Line)
+	}
+	for to > from {
+		prev := bytes.LastIndexByte(d.content[:to-1], '\n') + 1
+		if prev <= from {
+			break
+		}
+		trim := strings.TrimSpace(string(d.content[prev:to]))
+		if trim != "" && !strings.HasPrefix(trim, "#") && !(d.format == FrontmatterAttributes && isLineComment(trim)) {
+			break
+		}
+		to = prev
+	}
+	return from, to
+}
+
+// commentTail returns the end of the one-line entry d.fm.Content[i], from
+// the blanks before its line comment up to the line ending, so the comment
+// keeps its spacing when the value is replaced. It returns "" for entries
+// without a comment or spanning several lines.
+func (d *Document) commentTail(i int) string {
+	comment := d.fm.Content[i+1].LineComment
+	if comment == "" {
+		return ""
+	}
+	from, to := d.entrySpan(i)
+	line := strings.TrimRight(string(d.content[from:to]), "\r\n")
+	if strings.Contains(line, "\n") || !strings.HasSuffix(line, comment) {
+		return ""
+	}
+	value := strings.TrimRight(line[:len(line)-len(comment)], " \t")
+	return line[len(value):]
+}
+
+// tomlTopEnd returns where a new top-level key goes in TOML frontmatter:
+// before the first [table] or [[table]] and the blank lines above it, as
+// keys after the header would belong to the table.
+func (d *Document) tomlTopEnd() int {
+	for i := 0; i+1 < len(d.fm.Content); i += 2 {
+		if v := d.fm.Content[i+1]; (v.Kind == yaml.MappingNode || v.Kind == yaml.SequenceNode) && v.Style&yaml.FlowStyle == 0 {
+			lines := lineOffsets(d.content, d.fmStart, d.fmEnd)
+			n := d.fm.Content[i].Line - 1
+			if n >= len(lines) {
+				break
+			}
+			// keep the blank lines that set the table apart
+			for n > 0 && strings.TrimSpace(string(d.content[lines[n-1]:lines[n]])) == "" {
+				n--
+			}
+			return lines[n]
+		}
+	}
+	return d.fmEnd
+}
+
+// lineBreakAt returns the line ending needed to insert a line at the end
+// of a file whose last line has none.
+func (d *Document) lineBreakAt(at int) string {
+	if at > 0 && at == len(d.content) && d.content[at-1] != '\n' {
+		return d.newline
+	}
+	return ""
+}
+
+// lineOffsets returns the start offset of every line in content[start:end].
+func lineOffsets(content []byte, start, end int) []int {
+	offsets := []int{start}
+	for i := start; i < end; i++ {
+		if content[i] == '\n' && i+1 < end {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+func splice(content []byte, from, to int, text string) []byte {
+	out := make([]byte, 0, len(content)-(to-from)+len(text))
+	out = append(out, content[:from]...)
+	out = append(out, text...)
+	return append(out, content[to:]...)
+}
+
+// valueNode converts a Go value to a YAML node, borrowing the style of the
+// value it replaces. New strings are double-quoted and new ID lists use flow
+// style, matching the built-in templates. A string replacing a plain scalar
+// stays plain, e.g. date: 2025-01-15, and is quoted only when plain it would
+// not read back as a string, e.g. true replacing status: Proposed.
+func valueNode(value any, old *yaml.Node) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case *yaml.Node:
+		return v, nil
+	case string:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v, Style: yaml.DoubleQuotedStyle}
+		if old != nil && old.Kind == yaml.ScalarNode {
+			n.Style = old.Style
+			if old.Style == 0 {
+				// untagged, the value is written plain if it resolves as the old one did
+				plain := &yaml.Node{Kind: yaml.ScalarNode, Value: v}
+				if plain.ShortTag() == old.ShortTag() {
+					n.Tag = ""
+				}
+			}
+		}
+		if strings.Contains(v, "\n") && n.Style != yaml.LiteralStyle {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+		return n, nil
+	case IDList:
+		return valueNode([]string(v), old)
+	case []string:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		if old != nil && old.Kind == yaml.SequenceNode && old.Style&yaml.FlowStyle == 0 {
+			n.Style = 0
+		}
+		for _, s := range v {
+			// plain scalars keep ids like 0003 unquoted
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s})
+		}
+		return n, nil
+	}
+	var n yaml.Node
+	if err := n.Encode(value); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+var reBlockScalar = regexp.MustCompile(`^[|>][-+0-9]*(\s+#.*)?$`)
+
+// renderEntry formats a single "key: value" frontmatter entry, ending in a
+// newline. Block collections and block scalars are indented by two spaces.
+func renderEntry(key string, n *yaml.Node) (string, error) {
+	k, err := encodeNode(&yaml.Node{Kind: yaml.ScalarNode, Value: key})
+	if err != nil {
+		return "", err
+	}
+	v, err := encodeNode(n)
+	if err != nil {
+		return "", err
+	}
+	k = strings.TrimSuffix(k, "\n")
+	first, rest, _ := strings.Cut(v, "\n")
+	switch {
+	case (n.Kind == yaml.SequenceNode || n.Kind == yaml.MappingNode) && n.Style&yaml.FlowStyle == 0 && len(n.Content) > 0:
+		return k + ":\n" + indentLines(v, "  "), nil
+	case reBlockScalar.MatchString(first):
+		return k + ": " + first + "\n" + indentLines(rest, "  "), nil
+	}
+	return k + ": " + v, nil
+}
+
+func encodeNode(n *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func indentLines(s, indent string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = indent + l
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,189 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const documentFixture = `---
+# Decision record
+id: 0007
+title: 'Use Postgres'   # working title
+status: "Proposed"
+tags:
+  - db
+  - storage
+
+deciders: [alice, bob]
+---
+
+# ADR 0007: Use Postgres
+
+- Status: Proposed
+
+## Context
+We need a database.
+
+## Decision
+Postgres.
+
+### Details
+Version 16.
+
+## Consequences
+`
+
+// TestDocumentRoundTrip verifies an unedited document is written back
+// byte for byte.
+func TestDocumentRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "0007-use-postgres.md")
+	if err := os.WriteFile(path, []byte(documentFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := LoadDocument(path)
+	if err != nil {
+		t.Fatalf("LoadDocument failed: %v", err)
+	}
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != documentFixture {
+		t.Errorf("round trip changed the file:\n%s", got)
+	}
+	if keys := d.Keys(); len(keys) != 5 || keys[0] != "id" || keys[4] != "deciders" {
+		t.Errorf("Keys() = %v", keys)
+	}
+	if got := d.GetString("id"); got != "0007" {
+		t.Errorf("GetString(id) = %q, want 0007", got)
+	}
+}
+
+// TestDocumentSet verifies edits only touch the edited entry and keep its
+// style and comments.
+func TestDocumentSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value any
+		old   string
+		new   string
+	}{
+		{"double-quoted scalar", "status", "Accepted", "status: \"Proposed\"\n", "status: \"Accepted\"\n"},
+		{"single-quoted scalar keeps comment", "title", "Use PostgreSQL", "title: 'Use Postgres'   # working title\n", "title: 'Use PostgreSQL'   # working title\n"},
+		{"comment spacing kept for a new style", "title", &yaml.Node{Kind: yaml.ScalarNode, Value: "Use PostgreSQL"}, "title: 'Use Postgres'   # working title\n", "title: Use PostgreSQL   # working title\n"},
+		{"comment spacing kept for a list", "title", []string{"a", "b"}, "title: 'Use Postgres'   # working title\n", "title: [a, b]   # working title\n"},
+		{"block list stays block", "tags", []string{"db"}, "tags:\n  - db\n  - storage\n", "tags:\n  - db\n"},
+		{"flow list stays flow", "deciders", IDList{"carol"}, "deciders: [alice, bob]\n", "deciders: [carol]\n"},
+		{"new key is appended", "supersedes", IDList{"0003", "0004"}, "deciders: [alice, bob]\n---\n", "deciders: [alice, bob]\nsupersedes: [0003, 0004]\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDocument([]byte(documentFixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Set(tt.key, tt.value); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			want := replaceOnce(t, documentFixture, tt.old, tt.new)
+			if got := string(d.Bytes()); got != want {
+				t.Errorf("Set(%s):\n%s\nwant:\n%s", tt.key, got, want)
+			}
+		})
+	}
+
+	d, _ := ParseDocument([]byte("# ADR 0001: No frontmatter\n"))
+	if err := d.Set("status", "Accepted"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(d.Bytes()), "---\nstatus: \"Accepted\"\n---\n\n# ADR 0001: No frontmatter\n"; got != want {
+		t.Errorf("Set without frontmatter:\n%q\nwant %q", got, want)
+	}
+}
+
+// TestDocumentSetPlain verifies plain scalars stay plain, such as an
+// unquoted date, and are only quoted when plain they would not read back
+// as the string that was set.
+func TestDocumentSetPlain(t *testing.T) {
+	const in = "---\ndate: 2025-01-01\nstatus: Proposed\n---\n"
+	tests := []struct {
+		key, value, old, new string
+	}{
+		{"date", "2025-02-02", "date: 2025-01-01\n", "date: 2025-02-02\n"},
+		{"status", "Accepted", "status: Proposed\n", "status: Accepted\n"},
+		{"status", "true", "status: Proposed\n", "status: \"true\"\n"},
+		{"status", "Accepted: yes", "status: Proposed\n", "status: 'Accepted: yes'\n"},
+		{"date", "soon", "date: 2025-01-01\n", "date: soon\n"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDocument([]byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Set(tt.key, tt.value); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(d.Bytes()), replaceOnce(t, in, tt.old, tt.new); got != want {
+			t.Errorf("Set(%s, %q):\n%q\nwant %q", tt.key, tt.value, got, want)
+		}
+		if v := d.GetString(tt.key); v != tt.value {
+			t.Errorf("Set(%s, %q) reads back as %q", tt.key, tt.value, v)
+		}
+	}
+}
+
+// TestDocumentDelete verifies a key is removed along with its value lines.
+func TestDocumentDelete(t *testing.T) {
+	d, _ := ParseDocument([]byte(documentFixture))
+	if ok, err := d.Delete("tags"); !ok || err != nil {
+		t.Fatalf("Delete(tags) = %v, %v", ok, err)
+	}
+	want := replaceOnce(t, documentFixture, "tags:\n  - db\n  - storage\n", "")
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("Delete:\n%s\nwant:\n%s", got, want)
+	}
+	if ok, _ := d.Delete("missing"); ok {
+		t.Error("Delete of a missing key should report false")
+	}
+}
+
+// TestDocumentSections verifies heading ranges, nesting and SetSection.
+func TestDocumentSections(t *testing.T) {
+	d, _ := ParseDocument([]byte(documentFixture))
+	var titles []string
+	for _, s := range d.Sections() {
+		titles = append(titles, s.Title)
+	}
+	if got := strings.Join(titles, ","); got != "ADR 0007: Use Postgres,Context,Decision,Details,Consequences" {
+		t.Errorf("Sections() titles = %s", got)
+	}
+	s, ok := d.Section("decision")
+	if !ok || s.Text != "Postgres.\n\n### Details\nVersion 16.\n\n" {
+		t.Errorf("Section(decision) = %q, %v", s.Text, ok)
+	}
+
+	if err := d.SetSection("Context", "We need a relational database."); err != nil {
+		t.Fatal(err)
+	}
+	want := replaceOnce(t, documentFixture, "We need a database.\n", "We need a relational database.\n")
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("SetSection:\n%s\nwant:\n%s", got, want)
+	}
+	if err := d.SetSection("Alternatives", "x"); err == nil {
+		t.Error("SetSection on a missing heading should fail")
+	}
+}
+
+func replaceOnce(t *testing.T, s, old, new string) string {
+	t.Helper()
+	i := strings.Index(s, old)
+	if i < 0 {
+		t.Fatalf("fixture does not contain %q", old)
+	}
+	return s[:i] + new + s[i+len(old):]
+}
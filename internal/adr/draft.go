@@ -0,0 +1,68 @@
+package adr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DraftID stands in for the number of a draft ADR until it is promoted.
+const DraftID = "DRAFT"
+
+// draftsDir is the subdirectory of the ADR directory holding drafts.
+const draftsDir = "drafts"
+
+// DraftDir returns the directory holding unnumbered draft ADRs.
+func (m Manager) DraftDir() string {
+	return filepath.Join(m.Dir, draftsDir)
+}
+
+// Promote numbers the draft with the given slug (its file name in the
+// drafts directory, with or without its extension) and moves it into the
+// ADR directory. The frontmatter id and the "# ADR DRAFT:" heading get the
+// next free ID. It returns the path of the promoted ADR.
+func (m Manager) Promote(slug string) (string, error) {
+	slug = filepath.Base(slug)
+	var exts []string
+	if _, ok := markupOf(slug); ok {
+		exts = []string{filepath.Ext(slug)}
+		slug = strings.TrimSuffix(slug, exts[0])
+	} else {
+		for _, markup := range Markups {
+			exts = append(exts, "."+markup)
+		}
+	}
+	var draft string
+	var d *Document
+	var err error
+	for _, ext := range exts {
+		draft = filepath.Join(m.DraftDir(), slug+ext)
+		if d, err = LoadDocument(draft); !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("draft %q not found in %s", slug, m.DraftDir())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	path, _, err := m.withNextID(func(idStr string) (string, error) {
+		if err := setDocumentID(d, idStr); err != nil {
+			return "", err
+		}
+		path := filepath.Join(m.Dir, idStr+"-"+slug+filepath.Ext(draft))
+		return path, createFile(path, d.Bytes())
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := os.Remove(draft); err != nil {
+		return path, err
+	}
+	os.Remove(m.DraftDir()) // only succeeds once the last draft is promoted
+	return path, nil
+}
@@ -0,0 +1,51 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPromote verifies drafts are not numbered or scanned until promoted,
+// and then take the next free ID.
+func TestPromote(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+	draft, err := m.WriteNewADR("Use Rust", NewOptions{Draft: true, Date: "2025-01-15"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "drafts", "use-rust.md"); draft != want {
+		t.Errorf("draft path = %s, want %s", draft, want)
+	}
+	if _, err := m.WriteNewADR("Use Go", NewOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := Scan(dir); len(entries) != 1 {
+		t.Errorf("Scan found %d ADRs, want only the numbered one", len(entries))
+	}
+
+	path, err := m.Promote("use-rust")
+	if err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if want := filepath.Join(dir, "0002-use-rust.md"); path != want {
+		t.Errorf("promoted path = %s, want %s", path, want)
+	}
+	content, _ := os.ReadFile(path)
+	for _, want := range []string{"id: 0002\n", "# ADR 0002: Use Rust\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("promoted ADR missing %q:\n%s", want, content)
+		}
+	}
+	if _, err := os.Stat(draft); !os.IsNotExist(err) {
+		t.Error("draft should be removed after promotion")
+	}
+	if meta, err := ParseADR(path); err != nil || meta.Number != 2 {
+		t.Errorf("ParseADR number = %d, err %v", meta.Number, err)
+	}
+	if _, err := m.Promote("use-rust"); err == nil {
+		t.Error("promoting a missing draft should fail")
+	}
+}
@@ -0,0 +1,173 @@
+package adr
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportSchemaVersion is bumped whenever a field of ExportRecord is renamed,
+// removed or changes meaning. Adding fields does not change the version.
+const ExportSchemaVersion = 1
+
+// Export formats supported by Export, in addition to the markdown index.
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatJSONL    = "jsonl"
+)
+
+// ExportDocument is the top-level object of the JSON and YAML exports.
+type ExportDocument struct {
+	SchemaVersion int            `json:"schema_version" yaml:"schema_version"`
+	ADRs          []ExportRecord `json:"adrs" yaml:"adrs"`
+}
+
+// ExportRecord is the serialized form of a single ADR.
+type ExportRecord struct {
+	// SchemaVersion is only set in JSON Lines output, where every line must
+	// stand on its own.
+	SchemaVersion int `json:"schema_version,omitempty" yaml:"-"`
+
+	ID        string    `json:"id" yaml:"id"`
+	Number    int       `json:"number" yaml:"number"`
+	Title     string    `json:"title" yaml:"title"`
+	Status    string    `json:"status" yaml:"status"`
+	Date      string    `json:"date" yaml:"date"`
+	File      string    `json:"file" yaml:"file"`
+	Category  string    `json:"category,omitempty" yaml:"category,omitempty"`
+	Source    string    `json:"source,omitempty" yaml:"source,omitempty"`
+	Relations Relations `json:"relations" yaml:"relations"`
+	Extra     Fields    `json:"extra" yaml:"extra"`
+}
+
+// NewExportRecord converts an index entry to its export form.
+func NewExportRecord(e Entry) ExportRecord {
+	return ExportRecord{
+		ID:        e.ID,
+		Number:    e.Number,
+		Title:     e.Title,
+		Status:    e.Status,
+		Date:      e.Date,
+		File:      e.File,
+		Category:  e.Category,
+		Source:    e.Source,
+		Relations: e.Relations,
+		Extra:     e.Extra,
+	}
+}
+
+// Export writes entries to w in one of the data formats: json, yaml, csv or
+// jsonl.
+func Export(w io.Writer, entries []Entry, format string) error {
+	records := make([]ExportRecord, len(entries))
+	for i, e := range entries {
+		records[i] = NewExportRecord(e)
+	}
+	doc := ExportDocument{SchemaVersion: ExportSchemaVersion, ADRs: records}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			r.SchemaVersion = ExportSchemaVersion
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		return exportCSV(w, records)
+	}
+	return fmt.Errorf("unknown export format %q (want %s, %s, %s or %s)", format, FormatJSON, FormatYAML, FormatCSV, FormatJSONL)
+}
+
+// exportCSV writes one row per ADR. Relation and list values are joined with
+// ";". Category and source columns follow the relations when any ADR has
+// one, and extra fields become additional columns in first-seen order.
+func exportCSV(w io.Writer, records []ExportRecord) error {
+	var extraKeys []string
+	seen := map[string]bool{}
+	categories, sources := false, false
+	for _, r := range records {
+		categories = categories || r.Category != ""
+		sources = sources || r.Source != ""
+		for _, k := range r.Extra.Keys() {
+			if !seen[k] {
+				seen[k] = true
+				extraKeys = append(extraKeys, k)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := append([]string{"id", "number", "title", "status", "date", "file"}, RelationKinds...)
+	if categories {
+		header = append(header, "category")
+	}
+	if sources {
+		header = append(header, "source")
+	}
+	if err := cw.Write(append(header, extraKeys...)); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{r.ID, strconv.Itoa(r.Number), r.Title, r.Status, r.Date, r.File}
+		for _, kind := range RelationKinds {
+			row = append(row, strings.Join(r.Relations.Get(kind), ";"))
+		}
+		if categories {
+			row = append(row, r.Category)
+		}
+		if sources {
+			row = append(row, r.Source)
+		}
+		for _, k := range extraKeys {
+			row = append(row, FormatValue(r.Extra.Get(k), ";"))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatValue renders a frontmatter value as flat text: lists are joined
+// with sep and nested mappings are written as JSON.
+func FormatValue(v any, sep string) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []any:
+		parts := make([]string, len(x))
+		for i, item := range x {
+			parts[i] = FormatValue(item, sep)
+		}
+		return strings.Join(parts, sep)
+	case Fields:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,166 @@
+package adr
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func exportFixture(t *testing.T) []Entry {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("0001-first.md", `---
+id: 1
+title: "First, with comma"
+status: "Accepted"
+date: "2025-01-15"
+jira: ABC-12
+deciders: [alice, bob]
+component: "api"
+ticket: 0042
+---
+`)
+	write("0002-second.md", `---
+id: 2
+title: "Second"
+status: "Proposed"
+date: "2025-01-16"
+supersedes: [1]
+---
+`)
+	write("notes.md", "# Not an ADR\n")
+
+	entries, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// TestScanKeepsExtraFields verifies unknown frontmatter keys are preserved in
+// file order with their original text.
+func TestScanKeepsExtraFields(t *testing.T) {
+	entries := exportFixture(t)
+	extra := entries[0].Extra
+	if got := strings.Join(extra.Keys(), ","); got != "jira,deciders,component,ticket" {
+		t.Errorf("extra keys: got %s", got)
+	}
+	if extra.Get("ticket") != "0042" {
+		t.Errorf("zero-padded values should be kept as text, got %#v", extra.Get("ticket"))
+	}
+	if got := FormatValue(extra.Get("deciders"), ", "); got != "alice, bob" {
+		t.Errorf("deciders: got %q", got)
+	}
+	if entries[1].Extra.Len() != 0 {
+		t.Errorf("second ADR should have no extra fields, got %v", entries[1].Extra.Keys())
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportFixture(t), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SchemaVersion int `json:"schema_version"`
+		ADRs          []struct {
+			ID        string              `json:"id"`
+			Number    int                 `json:"number"`
+			Title     string              `json:"title"`
+			Relations map[string][]string `json:"relations"`
+			Extra     map[string]any      `json:"extra"`
+		} `json:"adrs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.SchemaVersion != ExportSchemaVersion || len(doc.ADRs) != 2 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+	if doc.ADRs[0].Extra["jira"] != "ABC-12" {
+		t.Errorf("extra fields missing from JSON: %v", doc.ADRs[0].Extra)
+	}
+	if got := doc.ADRs[1].Relations["supersedes"]; len(got) != 1 || got[0] != "1" {
+		t.Errorf("relations missing from JSON: %v", doc.ADRs[1].Relations)
+	}
+	// Extra keys keep file order.
+	if i, j := strings.Index(buf.String(), `"jira"`), strings.Index(buf.String(), `"deciders"`); i > j {
+		t.Error("extra fields should be serialized in file order")
+	}
+}
+
+func TestExportJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportFixture(t), FormatJSONL); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		if rec["schema_version"] != float64(ExportSchemaVersion) {
+			t.Errorf("every line should carry schema_version: %s", line)
+		}
+	}
+}
+
+func TestExportYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportFixture(t), FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, buf.String())
+	}
+	if raw["schema_version"] != ExportSchemaVersion {
+		t.Errorf("schema_version: got %v", raw["schema_version"])
+	}
+	if !strings.Contains(buf.String(), "    extra:\n      jira: ABC-12\n      deciders:\n") {
+		t.Errorf("extra fields should be serialized in file order:\n%s", buf.String())
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportFixture(t), FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(rows))
+	}
+	header := strings.Join(rows[0], ",")
+	if header != "id,number,title,status,date,file,supersedes,superseded_by,amends,amended_by,depends_on,relates_to,jira,deciders,component,ticket" {
+		t.Errorf("header: got %s", header)
+	}
+	if rows[1][2] != "First, with comma" || rows[1][13] != "alice;bob" {
+		t.Errorf("row 1: got %v", rows[1])
+	}
+	if rows[2][6] != "1" {
+		t.Errorf("row 2 supersedes: got %q", rows[2][6])
+	}
+
+	if err := Export(&buf, nil, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
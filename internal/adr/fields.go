@@ -0,0 +1,139 @@
+package adr
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fields is an ordered set of frontmatter keys and values. It is used for
+// frontmatter entries adrctl does not interpret itself (deciders, tags, ...),
+// so they can be carried through to the index and exports in file order.
+type Fields struct {
+	keys   []string
+	values map[string]any
+}
+
+// Keys returns the field names in the order they appear in the file.
+func (f Fields) Keys() []string { return f.keys }
+
+// Len returns the number of fields.
+func (f Fields) Len() int { return len(f.keys) }
+
+// Get returns the value of key, or nil when it is not set. Values are
+// strings, bools, numbers, []any or nested Fields.
+func (f Fields) Get(key string) any { return f.values[key] }
+
+// Has reports whether key is set.
+func (f Fields) Has(key string) bool {
+	_, ok := f.values[key]
+	return ok
+}
+
+// Set adds or replaces a field, keeping the original position of existing keys.
+func (f *Fields) Set(key string, value any) {
+	if f.values == nil {
+		f.values = map[string]any{}
+	}
+	if _, ok := f.values[key]; !ok {
+		f.keys = append(f.keys, key)
+	}
+	f.values[key] = value
+}
+
+func (f Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range f.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(f.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (f Fields) MarshalYAML() (any, error) {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range f.keys {
+		var v yaml.Node
+		if err := v.Encode(f.values[k]); err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, &v)
+	}
+	return n, nil
+}
+
+// fieldsFromNode collects the entries of a mapping node, skipping the keys
+// in skip.
+func fieldsFromNode(n *yaml.Node, skip map[string]bool) Fields {
+	var f Fields
+	if n == nil || n.Kind != yaml.MappingNode {
+		return f
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i].Value
+		if skip[key] {
+			continue
+		}
+		f.Set(key, nodeValue(n.Content[i+1]))
+	}
+	return f
+}
+
+// nodeValue converts a YAML node into a plain Go value. Scalars keep their
+// original text unless they are clearly numbers, booleans or null, so values
+// like dates and zero-padded ids ("0012") survive unchanged.
+func nodeValue(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return nodeValue(n.Alias)
+	case yaml.SequenceNode:
+		out := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			out = append(out, nodeValue(c))
+		}
+		return out
+	case yaml.MappingNode:
+		return fieldsFromNode(n, nil)
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			return nil
+		case "!!bool":
+			var b bool
+			if n.Decode(&b) == nil {
+				return b
+			}
+		case "!!int":
+			if len(n.Value) > 1 && strings.HasPrefix(n.Value, "0") {
+				return n.Value
+			}
+			var i int64
+			if n.Decode(&i) == nil {
+				return i
+			}
+		case "!!float":
+			var fl float64
+			if n.Decode(&fl) == nil {
+				return fl
+			}
+		}
+		return n.Value
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package adr
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// writeFileIfChanged writes data to path unless the file already holds
+// exactly that content. It reports whether the file was written.
+func writeFileIfChanged(path string, data []byte) (bool, error) {
+	current, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	return true, writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never observe a partially written file. An existing
+// file's permissions are preserved.
+func writeFileAtomic(path string, data []byte) error {
+	perm := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
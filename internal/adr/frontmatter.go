@@ -0,0 +1,382 @@
+package adr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter formats.
+const (
+	FrontmatterYAML = "yaml" // between --- lines; may end with ...
+	FrontmatterTOML = "toml" // between +++ lines, as Hugo writes it
+
+	// AsciiDoc and reStructuredText ADRs keep their metadata in the
+	// document itself rather than in frontmatter; see locateFields.
+	FrontmatterAttributes = "attributes" // AsciiDoc attribute entries in the document header
+	FrontmatterFields     = "fields"     // a reStructuredText field list at the top
+)
+
+// FrontmatterError describes frontmatter that is present but cannot be
+// read. Line is the 1-based line of the file where the problem is.
+type FrontmatterError struct {
+	Line int
+	Err  error
+}
+
+func (e *FrontmatterError) Error() string {
+	return fmt.Sprintf("frontmatter line %d: %v", e.Line, e.Err)
+}
+
+func (e *FrontmatterError) Unwrap() error { return e.Err }
+
+// fmBlock is the position of a frontmatter block in a file.
+type fmBlock struct {
+	format  string
+	start   int    // offset of the first line inside the block
+	end     int    // offset of the closing delimiter line
+	body    int    // offset of the first body byte
+	newline string // line ending of the opening delimiter, "\n" or "\r\n"
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// locateFrontmatter finds the frontmatter block at the start of content.
+// The file may start with a UTF-8 byte order mark and use CRLF line
+// endings. YAML frontmatter opens with --- and closes with --- or ...; TOML
+// frontmatter opens and closes with +++. The closing line may be the last
+// line of the file. ok is false when content has no frontmatter; an error
+// is returned when it looks like frontmatter that is not terminated or
+// does not start on the first line.
+func locateFrontmatter(content []byte) (b fmBlock, ok bool, err error) {
+	offset := 0
+	if bytes.HasPrefix(content, utf8BOM) {
+		offset = len(utf8BOM)
+	}
+	first, next := readLine(content, offset)
+	var closers []string
+	switch strings.TrimRight(first, " \t\r") {
+	case "---":
+		b.format, closers = FrontmatterYAML, []string{"---", "..."}
+	case "+++":
+		b.format, closers = FrontmatterTOML, []string{"+++"}
+	default:
+		return fmBlock{}, false, misplacedFrontmatter(content, offset)
+	}
+	b.start, b.newline = next, "\n"
+	if strings.HasSuffix(first, "\r") {
+		b.newline = "\r\n"
+	}
+
+	for pos := next; pos < len(content); {
+		line, after := readLine(content, pos)
+		trimmed := strings.TrimRight(line, " \t\r")
+		for _, c := range closers {
+			if trimmed == c {
+				b.end, b.body = pos, after
+				return b, true, nil
+			}
+		}
+		pos = after
+	}
+	return fmBlock{}, false, &FrontmatterError{Line: 1, Err: fmt.Errorf("frontmatter is not terminated by %s", closers[0])}
+}
+
+// misplacedFrontmatter reports frontmatter that follows blank lines instead
+// of starting the file, which would otherwise be read as markdown.
+func misplacedFrontmatter(content []byte, offset int) error {
+	for n := 1; offset < len(content); n++ {
+		line, next := readLine(content, offset)
+		switch strings.TrimSpace(line) {
+		case "":
+			offset = next
+			continue
+		case "---", "+++":
+			if n > 1 {
+				return &FrontmatterError{Line: n, Err: errors.New("frontmatter must start on the first line")}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// readLine returns the line starting at offset without its \n, and the
+// offset of the next line.
+func readLine(content []byte, offset int) (string, int) {
+	i := bytes.IndexByte(content[offset:], '\n')
+	if i < 0 {
+		return string(content[offset:]), len(content)
+	}
+	return string(content[offset : offset+i]), offset + i + 1
+}
+
+var reYAMLErrLine = regexp.MustCompile(`line (\d+)`)
+
+// decodeFrontmatter parses the block b of content into a mapping node. The
+// line numbers of the nodes count from the first line inside the block.
+func decodeFrontmatter(content []byte, b fmBlock) (*yaml.Node, error) {
+	text := content[b.start:b.end]
+	if b.format == FrontmatterAttributes || b.format == FrontmatterFields {
+		return parseFields(string(text), b.format), nil
+	}
+	if b.format == FrontmatterTOML {
+		fm, err := parseTOML(string(text))
+		var te *tomlError
+		if errors.As(err, &te) {
+			return nil, &FrontmatterError{Line: te.line + 1, Err: te.err}
+		}
+		return fm, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(text, &doc); err != nil {
+		line := 2 // the first line inside the block
+		if g := reYAMLErrLine.FindStringSubmatch(err.Error()); g != nil {
+			n, _ := strconv.Atoi(g[1])
+			line = n + 1
+		}
+		return nil, &FrontmatterError{Line: line, Err: err}
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, &FrontmatterError{Line: 2, Err: errors.New("frontmatter is not a mapping of keys to values")}
+	}
+	return doc.Content[0], nil
+}
+
+// tomlError is a TOML syntax error on a line of the frontmatter block.
+type tomlError struct {
+	line int
+	err  error
+}
+
+func (e *tomlError) Error() string { return fmt.Sprintf("line %d: %v", e.line, e.err) }
+
+var (
+	reTOMLBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	reTOMLInt     = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	reTOMLDate    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?([Zz]|[+-]\d{2}:\d{2})?)?$|^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+)
+
+// parseTOML reads TOML frontmatter into a YAML mapping node. go-toml checks
+// the text against the TOML spec, and the nodes are built from its syntax
+// tree so keys keep their order, line and trailing comment. Tables become
+// block mappings, arrays of tables block sequences of mappings, and dates
+// plain strings so they are written back bare.
+func parseTOML(text string) (*yaml.Node, error) {
+	var check map[string]any
+	if err := toml.Unmarshal([]byte(text), &check); err != nil {
+		line := 1
+		var de *toml.DecodeError
+		if errors.As(err, &de) {
+			line, _ = de.Position()
+		}
+		return nil, &tomlError{line: line, err: err}
+	}
+
+	p := &unstable.Parser{KeepComments: true}
+	p.Reset([]byte(text))
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	table := root
+	for p.NextExpression() {
+		e := p.Expression()
+		if e.Kind == unstable.Comment {
+			continue
+		}
+		keys, line := tomlKeys(p, e.Key())
+		switch e.Kind {
+		case unstable.Table:
+			table = tomlTable(root, keys, line)
+		case unstable.ArrayTable:
+			parent := tomlTable(root, keys[:len(keys)-1], line)
+			seq := tomlLookup(parent, keys[len(keys)-1])
+			if seq == nil {
+				seq = &yaml.Node{Kind: yaml.SequenceNode, Line: line}
+				parent.Content = append(parent.Content, tomlKeyNode(keys[len(keys)-1], line), seq)
+			}
+			table = &yaml.Node{Kind: yaml.MappingNode, Line: line}
+			seq.Content = append(seq.Content, table)
+		case unstable.KeyValue:
+			v, err := tomlNode(p, e.Value(), line)
+			if err != nil {
+				return nil, &tomlError{line: line, err: err}
+			}
+			if c := e.Next(); c != nil && c.Kind == unstable.Comment {
+				v.LineComment = strings.TrimSpace(string(c.Data))
+			}
+			parent := tomlTable(table, keys[:len(keys)-1], line)
+			parent.Content = append(parent.Content, tomlKeyNode(keys[len(keys)-1], line), v)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, &tomlError{line: 1, err: err}
+	}
+	return root, nil
+}
+
+// tomlKeys returns the parts of a possibly dotted key and the line it is on.
+func tomlKeys(p *unstable.Parser, it unstable.Iterator) ([]string, int) {
+	var keys []string
+	line := 0
+	for it.Next() {
+		k := it.Node()
+		if line == 0 {
+			line = p.Shape(k.Raw).Start.Line
+		}
+		keys = append(keys, string(k.Data))
+	}
+	return keys, line
+}
+
+func tomlKeyNode(key string, line int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, Line: line, Column: 1}
+}
+
+// tomlNode converts a TOML value to a YAML node.
+func tomlNode(p *unstable.Parser, v *unstable.Node, line int) (*yaml.Node, error) {
+	data := string(v.Data)
+	switch v.Kind {
+	case unstable.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: data, Style: yaml.DoubleQuotedStyle, Line: line}, nil
+	case unstable.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: data, Line: line}, nil
+	case unstable.Integer:
+		n, err := strconv.ParseInt(strings.ReplaceAll(data, "_", ""), 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(n, 10), Line: line}, nil
+	case unstable.Float:
+		f := strings.ReplaceAll(data, "_", "")
+		if i := strings.Index(f, "inf"); i >= 0 {
+			f = f[:i] + ".inf" // YAML spelling
+		} else if strings.HasSuffix(f, "nan") {
+			f = ".nan"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: f, Line: line}, nil
+	case unstable.LocalDate, unstable.LocalTime, unstable.LocalDateTime, unstable.DateTime:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: data, Line: line}, nil
+	case unstable.Array:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Line: line}
+		for it := v.Children(); it.Next(); {
+			if it.Node().Kind == unstable.Comment {
+				continue
+			}
+			c, err := tomlNode(p, it.Node(), line)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, c)
+		}
+		return n, nil
+	case unstable.InlineTable:
+		n := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle, Line: line}
+		for it := v.Children(); it.Next(); {
+			kv := it.Node()
+			if kv.Kind != unstable.KeyValue {
+				continue
+			}
+			keys, _ := tomlKeys(p, kv.Key())
+			c, err := tomlNode(p, kv.Value(), line)
+			if err != nil {
+				return nil, err
+			}
+			parent := tomlTable(n, keys[:len(keys)-1], line)
+			parent.Content = append(parent.Content, tomlKeyNode(keys[len(keys)-1], line), c)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("unsupported TOML value %s", v.Kind)
+}
+
+// tomlTable returns the table at path below t, creating it if needed. A
+// path through an array of tables continues in its last table.
+func tomlTable(t *yaml.Node, path []string, line int) *yaml.Node {
+	for _, k := range path {
+		next := tomlLookup(t, k)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Line: line}
+			t.Content = append(t.Content, tomlKeyNode(k, line), next)
+		}
+		if next.Kind == yaml.SequenceNode {
+			next = next.Content[len(next.Content)-1]
+		}
+		t = next
+	}
+	return t
+}
+
+func tomlLookup(t *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(t.Content); i += 2 {
+		if t.Content[i].Value == key {
+			return t.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// renderTOMLEntry formats a single "key = value" frontmatter entry, ending
+// in a newline.
+func renderTOMLEntry(key string, n *yaml.Node) (string, error) {
+	v, err := tomlValue(n)
+	if err != nil {
+		return "", err
+	}
+	entry := tomlKey(key) + " = " + v
+	if n.LineComment != "" {
+		entry += " " + n.LineComment
+	}
+	return entry + "\n", nil
+}
+
+func tomlKey(k string) string {
+	if reTOMLBareKey.MatchString(k) {
+		return k
+	}
+	return strconv.Quote(k)
+}
+
+func tomlValue(n *yaml.Node) (string, error) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		quoted := n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+		switch {
+		case n.Tag == "!!int" || n.Tag == "!!float" || n.Tag == "!!bool":
+			return n.Value, nil
+		case !quoted && (n.Value == "true" || n.Value == "false" || reTOMLInt.MatchString(n.Value) || reTOMLDate.MatchString(n.Value)):
+			return n.Value, nil
+		}
+		return strconv.Quote(n.Value), nil
+	case yaml.SequenceNode:
+		items := make([]string, len(n.Content))
+		for i, c := range n.Content {
+			v, err := tomlValue(c)
+			if err != nil {
+				return "", err
+			}
+			items[i] = v
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case yaml.MappingNode:
+		items := make([]string, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := tomlValue(n.Content[i+1])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(n.Content[i].Value)+" = "+v)
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("cannot write a %v node as TOML", n.Kind)
+}
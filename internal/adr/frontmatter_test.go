@@ -0,0 +1,264 @@
+package adr
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFrontmatterVariants verifies that frontmatter is found with CRLF line
+// endings, a byte order mark, a closing --- at the end of the file, YAML's
+// ... end marker and TOML +++ delimiters.
+func TestFrontmatterVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"lf", "---\nid: 7\ntitle: Use Go\nstatus: Accepted\ndate: 2025-01-15\n---\n\n# ADR 0007: Use Go\n"},
+		{"crlf", "---\r\nid: 7\r\ntitle: Use Go\r\nstatus: Accepted\r\ndate: 2025-01-15\r\n---\r\n\r\n# ADR 0007: Use Go\r\n"},
+		{"bom", "\xEF\xBB\xBF---\nid: 7\ntitle: Use Go\nstatus: Accepted\ndate: 2025-01-15\n---\n"},
+		{"closing at eof", "---\nid: 7\ntitle: Use Go\nstatus: Accepted\ndate: 2025-01-15\n---"},
+		{"dots", "---\nid: 7\ntitle: Use Go\nstatus: Accepted\ndate: 2025-01-15\n...\n# ADR 0007: Use Go\n"},
+		{"toml", "+++\nid = 7\ntitle = \"Use Go\" # the decision\nstatus = 'Accepted'\ndate = 2025-01-15\n+++\n"},
+		{"toml crlf", "+++\r\nid = 7\r\ntitle = \"Use Go\"\r\nstatus = \"Accepted\"\r\ndate = 2025-01-15\r\n+++\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "0007-use-go.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := ParseADR(path)
+			if err != nil {
+				t.Fatalf("ParseADR failed: %v", err)
+			}
+			if m.ID != "7" || m.Title != "Use Go" || m.Status != "Accepted" || m.Date != "2025-01-15" {
+				t.Errorf("got %+v", m)
+			}
+		})
+	}
+}
+
+// TestFrontmatterDiagnostics verifies that content which looks like
+// frontmatter but cannot be read is reported with its line, while the
+// metadata in the body is still used.
+func TestFrontmatterDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int
+		wantErr  string
+	}{
+		{"unterminated", "---\nid: 7\n\n# ADR 0007: Use Go\n", 1, "not terminated by ---"},
+		{"unterminated toml", "+++\nid = 7\n\n# ADR 0007: Use Go\n", 1, "not terminated by +++"},
+		{"leading blank line", "\n---\nid: 7\n---\n# ADR 0007: Use Go\n", 2, "must start on the first line"},
+		{"invalid yaml", "---\nid: 7\ntitle: [\n---\n# ADR 0007: Use Go\n", 3, "yaml"},
+		{"invalid toml", "+++\nid = 7\ntitle = Use Go\n+++\n# ADR 0007: Use Go\n", 3, "toml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "0007-use-go.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := ParseADR(path)
+			var fe *FrontmatterError
+			if !errors.As(err, &fe) {
+				t.Fatalf("expected a *FrontmatterError, got %v", err)
+			}
+			if fe.Line != tt.wantLine || !strings.Contains(fe.Error(), tt.wantErr) {
+				t.Errorf("got line %d %q, want line %d containing %q", fe.Line, fe.Error(), tt.wantLine, tt.wantErr)
+			}
+			if m.Title != "Use Go" {
+				t.Errorf("body metadata should still be parsed, got title %q", m.Title)
+			}
+			if _, err := ParseDocument([]byte(tt.content)); err == nil {
+				t.Error("ParseDocument should refuse to edit unreadable frontmatter")
+			}
+		})
+	}
+}
+
+// TestDocumentEditVariants verifies that edits keep CRLF line endings and
+// the byte order mark, and are written as TOML in TOML frontmatter, ahead
+// of any [table].
+func TestDocumentEditVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "crlf",
+			content: "---\r\nid: 7\r\nstatus: Proposed\r\n---\r\n# ADR 0007\r\n",
+			want:    "---\r\nid: 7\r\nstatus: Accepted\r\nsupersedes: [0003]\r\n---\r\n# ADR 0007\r\n",
+		},
+		{
+			name:    "bom without frontmatter",
+			content: "\xEF\xBB\xBF# ADR 0007\n",
+			want:    "\xEF\xBB\xBF---\nstatus: \"Accepted\"\nsupersedes: [0003]\n---\n\n# ADR 0007\n",
+		},
+		{
+			name:    "toml",
+			content: "+++\nid = 7\nstatus = \"Proposed\"\n\n[params]\nowner = \"alice\"\n+++\n",
+			want:    "+++\nid = 7\nstatus = \"Accepted\"\nsupersedes = [\"0003\"]\n\n[params]\nowner = \"alice\"\n+++\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDocument([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Set("status", "Accepted"); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Set("supersedes", IDList{"0003"}); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(d.Bytes()); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseTOML covers the TOML value types used in frontmatter.
+func TestParseTOML(t *testing.T) {
+	text := `# Hugo frontmatter
+id = 1_000
+title = "Quote \"this\" \u00e9"
+path = 'C:\adr'
+draft = false
+weight = 1.5
+date = 2025-01-15T10:00:00Z
+tags = [
+  "go",  # language
+  'tooling',
+]
+author = { name = "Alice", email = "a@example.com" }
+site.section = "decisions"
+notes = """
+first
+second"""
+
+[params]
+owner = "platform"
+`
+	fm, err := parseTOML(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		ID     int               `yaml:"id"`
+		Title  string            `yaml:"title"`
+		Path   string            `yaml:"path"`
+		Draft  bool              `yaml:"draft"`
+		Weight float64           `yaml:"weight"`
+		Date   string            `yaml:"date"`
+		Tags   []string          `yaml:"tags"`
+		Author map[string]string `yaml:"author"`
+		Site   map[string]string `yaml:"site"`
+		Notes  string            `yaml:"notes"`
+		Params map[string]string `yaml:"params"`
+	}
+	if err := fm.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 1000 || got.Title != `Quote "this" é` || got.Path != `C:\adr` || got.Draft || got.Weight != 1.5 ||
+		got.Date != "2025-01-15T10:00:00Z" || strings.Join(got.Tags, ",") != "go,tooling" ||
+		got.Author["email"] != "a@example.com" || got.Site["section"] != "decisions" ||
+		got.Notes != "first\nsecond" || got.Params["owner"] != "platform" {
+		t.Errorf("got %+v", got)
+	}
+	for _, bad := range []string{
+		"a = 1\na = 2\n",
+		"[t]\na = 1\n[t]\nb = 2\n",
+		"t = { a = 1 }\nt.b = 2\n",
+		"a = \"\\q\"\n",
+		"a = 1979-05-27T25:00:00\n",
+	} {
+		if _, err := parseTOML(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+// TestParseTOMLSpec covers TOML forms beyond plain key/value pairs:
+// multi-line strings, quoted dotted keys, nested inline tables, every
+// date-time form, integer bases and arrays of tables.
+func TestParseTOMLSpec(t *testing.T) {
+	text := `basic = """
+Roses are red \
+  Violets are blue"""
+literal = '''
+C:\adr\'raw''''
+site."page one".title = "Intro"
+owner = { name = { first = "Ada" }, tags = ["a", "b"] }
+odt = 1979-05-27T07:32:00-07:00
+ldt = 1979-05-27 07:32:00.999
+ld = 1979-05-27
+lt = 07:32:00
+hex = 0xDEAD_beef
+oct = 0o755
+bin = 0b1101
+neg = -inf
+
+[[reviews]]
+by = "alice"
+
+[[reviews]]
+by = "bob"
+`
+	fm, err := parseTOML(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Basic   string                       `yaml:"basic"`
+		Literal string                       `yaml:"literal"`
+		Site    map[string]map[string]string `yaml:"site"`
+		Owner   struct {
+			Name map[string]string `yaml:"name"`
+			Tags []string          `yaml:"tags"`
+		} `yaml:"owner"`
+		ODT, LDT, LD, LT string
+		Hex, Oct, Bin    int64
+		Neg              float64
+		Reviews          []map[string]string `yaml:"reviews"`
+	}
+	if err := fm.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Basic != "Roses are red Violets are blue" || got.Literal != `C:\adr\'raw'` ||
+		got.Site["page one"]["title"] != "Intro" || got.Owner.Name["first"] != "Ada" || strings.Join(got.Owner.Tags, ",") != "a,b" {
+		t.Errorf("strings and tables: %+v", got)
+	}
+	if got.ODT != "1979-05-27T07:32:00-07:00" || got.LDT != "1979-05-27 07:32:00.999" || got.LD != "1979-05-27" || got.LT != "07:32:00" {
+		t.Errorf("dates should keep their text: %+v", got)
+	}
+	if got.Hex != 0xdeadbeef || got.Oct != 0o755 || got.Bin != 13 || !math.IsInf(got.Neg, -1) {
+		t.Errorf("numbers: %+v", got)
+	}
+	if len(got.Reviews) != 2 || got.Reviews[1]["by"] != "bob" {
+		t.Errorf("arrays of tables: %+v", got.Reviews)
+	}
+
+	// a new key goes above the arrays of tables, and comments are kept
+	d, err := ParseDocument([]byte("+++\nstatus = \"Proposed\"   # draft\n\n[[reviews]]\nby = \"alice\"\n+++\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("status", "Accepted"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("date", "2025-01-15"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(d.Bytes()), "+++\nstatus = \"Accepted\"   # draft\ndate = \"2025-01-15\"\n\n[[reviews]]\nby = \"alice\"\n+++\n"; got != want {
+		t.Errorf("edit:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package adr
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// EntryGroup is a set of entries sharing a field value, as returned by the
+// groupBy template function.
+type EntryGroup struct {
+	Key     string
+	Entries []Entry
+}
+
+// TemplateFuncs returns the functions available to ADR and index templates:
+//
+//	date LAYOUT VALUE     reformat a YYYY-MM-DD date with a Go time layout
+//	now LAYOUT            current time in a Go time layout
+//	groupBy FIELD ENTRIES group entries by a field, in first-seen order
+//	sortBy FIELD ENTRIES  sort entries by a field (ids and numbers numerically)
+//	where FIELD VALUE ENTRIES  keep entries whose field equals VALUE (case-insensitive)
+//	reverse ENTRIES       reverse the order of entries
+//	lower, upper, title   change case
+//	default DEFAULT VALUE use DEFAULT when VALUE is empty
+//	join SEP LIST         join a list with SEP
+//	mdEscape TEXT         escape markdown control characters
+//	underline CHAR TEXT   a reStructuredText title underline as wide as TEXT
+//
+// FIELD is any name accepted by Entry.Field, including custom frontmatter keys.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"date":      formatDate,
+		"now":       func(layout string) string { return time.Now().Format(layout) },
+		"groupBy":   groupBy,
+		"sortBy":    sortBy,
+		"where":     where,
+		"reverse":   reverseEntries,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"title":     titleCase,
+		"default":   defaultValue,
+		"join":      join,
+		"mdEscape":  mdEscape,
+		"underline": underline,
+	}
+}
+
+// formatDate reformats an ISO date (or time.Time) with a Go time layout.
+// Values that are not dates are returned unchanged.
+func formatDate(layout string, v any) string {
+	switch x := v.(type) {
+	case time.Time:
+		return x.Format(layout)
+	case string:
+		for _, in := range []string{"2006-01-02", time.RFC3339} {
+			if t, err := time.Parse(in, strings.TrimSpace(x)); err == nil {
+				return t.Format(layout)
+			}
+		}
+		return x
+	}
+	return fmt.Sprint(v)
+}
+
+func groupBy(field string, entries []Entry) []EntryGroup {
+	var groups []EntryGroup
+	pos := map[string]int{}
+	for _, e := range entries {
+		key := e.Field(field)
+		i, ok := pos[key]
+		if !ok {
+			i = len(groups)
+			pos[key] = i
+			groups = append(groups, EntryGroup{Key: key})
+		}
+		groups[i].Entries = append(groups[i].Entries, e)
+	}
+	return groups
+}
+
+func sortBy(field string, entries []Entry) []Entry {
+	out := append([]Entry(nil), entries...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Field(field), out[j].Field(field)
+		an, aerr := strconv.Atoi(a)
+		bn, berr := strconv.Atoi(b)
+		if aerr == nil && berr == nil {
+			return an < bn
+		}
+		return strings.ToLower(a) < strings.ToLower(b)
+	})
+	return out
+}
+
+func where(field, value string, entries []Entry) []Entry {
+	var out []Entry
+	for _, e := range entries {
+		if strings.EqualFold(e.Field(field), value) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func reverseEntries(entries []Entry) []Entry {
+	out := make([]Entry, len(entries))
+	for i, e := range entries {
+		out[len(entries)-1-i] = e
+	}
+	return out
+}
+
+// titleCase upper-cases the first letter of every word.
+func titleCase(s string) string {
+	upper := true
+	return strings.Map(func(r rune) rune {
+		if upper {
+			r = unicode.ToUpper(r)
+		}
+		upper = unicode.IsSpace(r) || r == '-' || r == '_'
+		return r
+	}, s)
+}
+
+func defaultValue(def, v any) any {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	}
+	return v
+}
+
+// join joins any list (IDList, []string, []any) with sep.
+func join(sep string, v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return FormatValue(v, sep)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = FormatValue(rv.Index(i).Interface(), sep)
+	}
+	return strings.Join(parts, sep)
+}
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+	`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `|`, `\|`,
+)
+
+// mdEscape escapes characters that would otherwise be read as markdown.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
@@ -0,0 +1,111 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	entries := []Entry{
+		{Number: 2, ID: "0002", Title: "Beta", Status: "Proposed", Date: "2025-02-01"},
+		{Number: 10, ID: "0010", Title: "alpha", Status: "Accepted", Date: "2025-01-15"},
+		{Number: 1, ID: "0001", Title: "Gamma", Status: "Accepted", Date: "2024-12-31"},
+	}
+	entries[0].Extra.Set("tags", []any{"db", "infra"})
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"date", `{{date "Jan 2, 2006" "2025-01-15"}}`, "Jan 15, 2025"},
+		{"date passthrough", `{{date "2006" "unknown"}}`, "unknown"},
+		{"lower upper title", `{{lower "ABC"}} {{upper "abc"}} {{title "use postgres-db"}}`, "abc ABC Use Postgres-Db"},
+		{"default", `{{default "n/a" ""}} {{default "n/a" "set"}}`, "n/a set"},
+		{"join list", `{{join " / " ((index . 0).Extra.Get "tags")}}`, "db / infra"},
+		{"mdEscape", `{{mdEscape "a*b_c|[d]"}}`, `a\*b\_c\|\[d\]`},
+		{"sortBy number", `{{range sortBy "id" .}}{{.ID}} {{end}}`, "0001 0002 0010 "},
+		{"sortBy title", `{{range sortBy "title" .}}{{.Title}} {{end}}`, "alpha Beta Gamma "},
+		{"reverse", `{{range reverse .}}{{.ID}} {{end}}`, "0001 0010 0002 "},
+		{"where", `{{range where "status" "accepted" .}}{{.ID}} {{end}}`, "0010 0001 "},
+		{"groupBy", `{{range groupBy "status" .}}{{.Key}}={{len .Entries}} {{end}}`, "Proposed=1 Accepted=2 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := template.New(tt.name).Funcs(TemplateFuncs()).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			var b strings.Builder
+			if err := tpl.Execute(&b, entries); err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestCustomIndexTemplate renders the index with a user-supplied template
+// that uses the function library.
+func TestCustomIndexTemplate(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "index.tmpl.md")
+	custom := `# {{default "Decisions" .ProjectName}}
+{{range groupBy "status" (sortBy "id" .Entries)}}
+## {{.Key}}
+{{range .Entries}}- [{{.ID}}](./{{.File}}) {{.Title}} ({{date "02 Jan 2006" .Date}})
+{{end}}{{end}}`
+	if err := os.WriteFile(tmplPath, []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := RenderIndex(&b, indexFixture(), IndexOptions{Template: tmplPath}); err != nil {
+		t.Fatalf("RenderIndex failed: %v", err)
+	}
+	want := `# Decisions
+
+## Accepted
+- [0001](./0001-first.md) First Decision (15 Jan 2025)
+
+## Proposed
+- [0002](./0002-second.md) Second Decision (16 Jan 2025)
+`
+	if b.String() != want {
+		t.Errorf("custom index:\ngot:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if strings.Contains(b.String(), "About ADR Management") {
+		t.Error("custom template should replace the built-in boilerplate")
+	}
+
+	if err := RenderIndex(&b, indexFixture(), IndexOptions{Template: filepath.Join(dir, "missing.md")}); err == nil {
+		t.Error("expected error for missing template")
+	}
+}
+
+// TestADRTemplateFuncs verifies the function library is available when
+// creating ADRs from custom templates.
+func TestADRTemplateFuncs(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "custom.md")
+	if err := os.WriteFile(tmplPath, []byte("# ADR {{.ID}}: {{title .Title}}\n\nProposed on {{date \"January 2, 2006\" .Date}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := Manager{Dir: dir}
+	path, err := m.WriteNewADR("use shared cache", NewOptions{Template: tmplPath, Date: "2025-03-04"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# ADR 0001: Use Shared Cache\n\nProposed on March 4, 2025\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
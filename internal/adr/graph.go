@@ -0,0 +1,180 @@
+package adr
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Graph output formats supported by WriteGraph.
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// Edge is a directed relationship between two ADRs, e.g. 0003 supersedes 0001.
+type Edge struct {
+	From Entry
+	To   Entry
+	Kind string // supersedes | amends | depends_on | relates_to
+}
+
+// inverseKinds maps backward-looking relation keys to the forward key used
+// for edges, so a link declared on either side produces one edge.
+var inverseKinds = map[string]string{
+	"superseded_by": "supersedes",
+	"amended_by":    "amends",
+}
+
+// Edges collects the relationships between the given entries. References to
+// ADRs that are not in entries are ignored.
+func Edges(entries []Entry) []Edge {
+	var edges []Edge
+	seen := map[string]bool{}
+	add := func(from, to Entry, kind string) {
+		key := from.File + "\x00" + to.File + "\x00" + kind
+		if from.File == to.File || seen[key] {
+			return
+		}
+		seen[key] = true
+		edges = append(edges, Edge{From: from, To: to, Kind: kind})
+	}
+	for _, e := range entries {
+		for _, kind := range RelationKinds {
+			for _, id := range e.Get(kind) {
+				other, ok := Lookup(entries, id)
+				if !ok {
+					continue
+				}
+				if fwd, inverse := inverseKinds[kind]; inverse {
+					add(other, e, fwd)
+				} else {
+					add(e, other, kind)
+				}
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].From.ID != edges[j].From.ID {
+			return entryLess(edges[i].From, edges[j].From)
+		}
+		return entryLess(edges[i].To, edges[j].To)
+	})
+	return edges
+}
+
+// WriteGraph renders the decision graph in the given format (dot or mermaid).
+func WriteGraph(w io.Writer, entries []Entry, format string) error {
+	switch strings.ToLower(format) {
+	case GraphDOT:
+		return WriteDOT(w, entries)
+	case GraphMermaid, "":
+		return WriteMermaid(w, entries)
+	}
+	return fmt.Errorf("unknown graph format %q (want %s or %s)", format, GraphDOT, GraphMermaid)
+}
+
+// statusClass buckets a free-form status into a class used for coloring.
+func statusClass(status string) string {
+	s := strings.ToLower(strings.TrimSpace(status))
+	for _, c := range []string{"superseded", "deprecated", "rejected", "accepted", "proposed", "draft"} {
+		if strings.HasPrefix(s, c) {
+			return c
+		}
+	}
+	return "other"
+}
+
+// statusColors are the fill and stroke colors for each status class.
+var statusColors = map[string][2]string{
+	"accepted":   {"#c8e6c9", "#2e7d32"},
+	"proposed":   {"#fff9c4", "#f9a825"},
+	"draft":      {"#f5f5f5", "#9e9e9e"},
+	"superseded": {"#e0e0e0", "#616161"},
+	"deprecated": {"#ffe0b2", "#ef6c00"},
+	"rejected":   {"#ffcdd2", "#c62828"},
+	"other":      {"#ffffff", "#424242"},
+}
+
+var statusClassOrder = []string{"accepted", "proposed", "draft", "superseded", "deprecated", "rejected", "other"}
+
+func edgeLabel(kind string) string {
+	return strings.ReplaceAll(kind, "_", " ")
+}
+
+// WriteDOT renders the decision graph as Graphviz DOT.
+func WriteDOT(w io.Writer, entries []Entry) error {
+	var b strings.Builder
+	b.WriteString("digraph adr {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, e := range entries {
+		c := statusColors[statusClass(e.Status)]
+		fmt.Fprintf(&b, "  %s [label=%s, fillcolor=%q, color=%q, tooltip=%s];\n",
+			dotQuote(e.ID), dotQuote(e.ID+": "+e.Title), c[0], c[1], dotQuote(e.Status))
+	}
+	for _, edge := range Edges(entries) {
+		attrs := "label=" + dotQuote(edgeLabel(edge.Kind))
+		if edge.Kind == "relates_to" {
+			attrs += ", style=dashed, dir=none"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From.ID), dotQuote(edge.To.ID), attrs)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// WriteMermaid renders the decision graph as a Mermaid flowchart.
+func WriteMermaid(w io.Writer, entries []Entry) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	classes := map[string][]string{}
+	for _, e := range entries {
+		node := mermaidNode(e)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", node, mermaidEscape(e.ID+": "+e.Title))
+		cls := statusClass(e.Status)
+		classes[cls] = append(classes[cls], node)
+	}
+	for _, edge := range Edges(entries) {
+		arrow := "-->"
+		if edge.Kind == "relates_to" {
+			arrow = "-.-"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", mermaidNode(edge.From), arrow, edgeLabel(edge.Kind), mermaidNode(edge.To))
+	}
+	for _, cls := range statusClassOrder {
+		nodes := classes[cls]
+		if len(nodes) == 0 {
+			continue
+		}
+		c := statusColors[cls]
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:%s\n", cls, c[0], c[1])
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(nodes, ","), cls)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidNode derives a Mermaid-safe node id from the ADR id.
+func mermaidNode(e Entry) string {
+	id := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, e.ID)
+	return "adr" + id
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,101 @@
+package adr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func graphFixture() []Entry {
+	return []Entry{
+		{Number: 1, ID: "0001", Title: "Use MySQL", Status: "Superseded by ADR 0002", File: "0001-use-mysql.md",
+			Relations: Relations{SupersededBy: IDList{"2"}}},
+		{Number: 2, ID: "0002", Title: `Use "Postgres"`, Status: "Accepted", File: "0002-use-postgres.md",
+			Relations: Relations{Supersedes: IDList{"1"}, RelatesTo: IDList{"3"}}},
+		{Number: 3, ID: "0003", Title: "Connection pooling", Status: "Proposed", File: "0003-connection-pooling.md",
+			Relations: Relations{Amends: IDList{"2"}, DependsOn: IDList{"99"}}},
+	}
+}
+
+// TestEdgesDeduplicatesInverseLinks verifies that a supersession recorded on
+// both ADRs yields a single edge and that dangling references are dropped.
+func TestEdgesDeduplicatesInverseLinks(t *testing.T) {
+	edges := Edges(graphFixture())
+	var got []string
+	for _, e := range edges {
+		got = append(got, e.From.ID+" "+e.Kind+" "+e.To.ID)
+	}
+	want := []string{
+		"0002 supersedes 0001",
+		"0002 relates_to 0003",
+		"0003 amends 0002",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("edges:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestWriteGraphFormats(t *testing.T) {
+	var dot bytes.Buffer
+	if err := WriteGraph(&dot, graphFixture(), "dot"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph adr {",
+		`"0002" [label="0002: Use \"Postgres\"", fillcolor="#c8e6c9"`,
+		`"0002" -> "0001" [label="supersedes"];`,
+		`"0002" -> "0003" [label="relates to", style=dashed, dir=none];`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot.String())
+		}
+	}
+
+	var mmd bytes.Buffer
+	if err := WriteGraph(&mmd, graphFixture(), "mermaid"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"flowchart LR",
+		`adr0002["0002: Use #quot;Postgres#quot;"]`,
+		"adr0002 -->|supersedes| adr0001",
+		"adr0003 -->|amends| adr0002",
+		"class adr0001 superseded",
+		"class adr0003 proposed",
+	} {
+		if !strings.Contains(mmd.String(), want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, mmd.String())
+		}
+	}
+
+	if err := WriteGraph(&mmd, nil, "svg"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestIndexEmbedsGraph(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "index.md")
+	if err := WriteIndexOptions(out, graphFixture(), IndexOptions{Graph: true}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "## Decision Graph\n\n```mermaid\nflowchart LR\n") {
+		t.Errorf("index should embed the Mermaid graph:\n%s", content)
+	}
+
+	if err := WriteIndex(out, graphFixture(), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	content, err = os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "```mermaid") {
+		t.Error("graph should only be embedded when requested")
+	}
+}
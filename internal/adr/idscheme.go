@@ -0,0 +1,262 @@
+package adr
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ID scheme kinds.
+const (
+	IDSequential = "sequential" // 0001, 0002, ... (the default)
+	IDDate       = "date"       // 2026-10-16, with -2, -3, ... for more on a day
+	IDTimestamp  = "timestamp"  // 20261016153000
+	IDULID       = "ulid"       // 01JA2Z3X4Y5W6V7T8S9R0QPNMK
+)
+
+// IDScheme describes what ADR IDs look like. The zero value is the classic
+// four-digit sequence.
+type IDScheme struct {
+	Kind   string `yaml:"kind"`   // sequential, date, timestamp or ulid
+	Width  int    `yaml:"width"`  // zero padding of sequential numbers; default 4
+	Prefix string `yaml:"prefix"` // prepended to every ID, e.g. SEC-
+	// Format is the Go time layout of date and timestamp IDs, built from
+	// 2006, 01, 02, 15, 04 and 05. Defaults to 2006-01-02 for date and
+	// 20060102150405 for timestamp.
+	Format string `yaml:"format"`
+}
+
+var rePrefix = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*[-_]?$`)
+
+// Validate checks the scheme settings.
+func (s IDScheme) Validate() error {
+	switch s.kind() {
+	case IDSequential, IDULID:
+		if s.Format != "" {
+			return fmt.Errorf("id.format only applies to date and timestamp IDs")
+		}
+	case IDDate, IDTimestamp:
+		sample := time.Date(2026, 10, 16, 15, 4, 5, 0, time.UTC).Format(s.layout())
+		if !regexp.MustCompile(`^`+layoutPattern(s.layout())+`$`).MatchString(sample) ||
+			!regexp.MustCompile(`^[0-9A-Za-z._-]+$`).MatchString(sample) {
+			return fmt.Errorf("id.format %q may only use 2006, 01, 02, 15, 04, 05 and the separators - _ .", s.layout())
+		}
+	default:
+		return fmt.Errorf("unknown id.kind %q (want %s, %s, %s or %s)", s.Kind, IDSequential, IDDate, IDTimestamp, IDULID)
+	}
+	if s.Width < 0 || s.Width > 18 {
+		return fmt.Errorf("id.width must be between 1 and 18, or 0 for the default of 4")
+	}
+	if s.Prefix != "" && !rePrefix.MatchString(s.Prefix) {
+		return fmt.Errorf("id.prefix %q must be letters and digits, optionally ending in - or _", s.Prefix)
+	}
+	return nil
+}
+
+func (s IDScheme) kind() string {
+	if s.Kind == "" {
+		return IDSequential
+	}
+	return strings.ToLower(s.Kind)
+}
+
+func (s IDScheme) width() int {
+	if s.Width == 0 {
+		return 4
+	}
+	return s.Width
+}
+
+func (s IDScheme) layout() string {
+	switch {
+	case s.Format != "":
+		return s.Format
+	case s.kind() == IDDate:
+		return "2006-01-02"
+	}
+	return "20060102150405"
+}
+
+// Sequential reports whether IDs are numbered.
+func (s IDScheme) Sequential() bool { return s.kind() == IDSequential }
+
+// FormatNumber returns the sequential ID for n, e.g. SEC-0012.
+func (s IDScheme) FormatNumber(n int) string {
+	return fmt.Sprintf("%s%0*d", s.Prefix, s.width(), n)
+}
+
+// New returns a fresh ID for a scheme that is not sequential, given the
+// IDs already taken (compared with idKey).
+func (s IDScheme) New(now time.Time, taken map[string]bool) string {
+	if s.kind() == IDULID {
+		return s.Prefix + newULID(now)
+	}
+	return suffixID(s.Prefix+now.Format(s.layout()), taken)
+}
+
+// idMatcher recognizes IDs of one scheme.
+type idMatcher struct {
+	scheme IDScheme
+	file   *regexp.Regexp // an ID at the start of a file name
+	whole  *regexp.Regexp // a complete ID
+}
+
+func (s IDScheme) matcher() idMatcher {
+	var body, boundary string
+	switch s.kind() {
+	case IDSequential:
+		body = `\d+`
+	case IDULID:
+		body, boundary = `[0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{26}`, `(?:[-_.]|$)`
+	default:
+		body, boundary = layoutPattern(s.layout())+`(?:-\d+)?`, `(?:[-_.]|$)`
+	}
+	prefix := ""
+	if s.Prefix != "" {
+		prefix = `(?i:` + regexp.QuoteMeta(s.Prefix) + `)`
+	}
+	return idMatcher{
+		scheme: s,
+		file:   regexp.MustCompile(`^(` + prefix + body + `)` + boundary),
+		whole:  regexp.MustCompile(`^` + prefix + body + `$`),
+	}
+}
+
+// fileID returns the ID a file name starts with, in canonical form, e.g.
+// SEC-0001 for sec-1-use-go.md.
+func (m idMatcher) fileID(name string) (string, bool) {
+	id, _, ok := m.splitFile(name)
+	return id, ok
+}
+
+// splitFile splits a file name into its canonical ID and the rest of the
+// name, e.g. "SEC-0001" and "-use-go.md".
+func (m idMatcher) splitFile(name string) (string, string, bool) {
+	g := m.file.FindStringSubmatch(name)
+	if g == nil {
+		return "", "", false
+	}
+	rest := name[len(g[1]):]
+	if m.scheme.Sequential() {
+		n := idNumber(g[1])
+		if n == 0 {
+			return "", "", false
+		}
+		return m.scheme.FormatNumber(n), rest, true
+	}
+	return m.canonical(g[1]), rest, true
+}
+
+// canonical returns id with the configured prefix spelling and, for ULIDs,
+// in upper case.
+func (m idMatcher) canonical(id string) string {
+	body := id[len(m.scheme.Prefix):]
+	if m.scheme.kind() == IDULID {
+		body = strings.ToUpper(body)
+	}
+	return m.scheme.Prefix + body
+}
+
+// entryID returns the ID of an ADR from the ID written in the file (meta,
+// possibly empty) and the ID in its file name.
+func (m idMatcher) entryID(written, fileID string) (string, int) {
+	if m.scheme.Sequential() {
+		n := idNumber(written)
+		if n == 0 {
+			n = idNumber(fileID)
+		}
+		return m.scheme.FormatNumber(n), n
+	}
+	if written = strings.TrimSpace(written); m.whole.MatchString(written) {
+		return m.canonical(written), 0
+	}
+	return fileID, 0
+}
+
+// sameID reports whether an ID written in an ADR refers to fileID. For
+// sequential schemes the prefix may be left out ("7" for SEC-0007).
+func (m idMatcher) sameID(written, fileID string) bool {
+	if m.scheme.Sequential() {
+		k := idKey(written)
+		g := reNumberedID.FindStringSubmatch(k)
+		return g != nil && idNumber(k) == idNumber(fileID) && (g[1] == "" || k == idKey(fileID))
+	}
+	written = strings.TrimSpace(written)
+	return m.whole.MatchString(written) && m.canonical(written) == fileID
+}
+
+// suffixID returns id, or id with the first free -2, -3, ... suffix when
+// it is already taken.
+func suffixID(id string, taken map[string]bool) string {
+	base := id
+	for i := 2; taken[idKey(id)]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	return id
+}
+
+var reNumberedID = regexp.MustCompile(`^([A-Za-z]+[-_]?)?(\d+)$`)
+
+// idNumber returns the number in an ID such as "7", "0007" or "SEC-0007",
+// or 0 when the ID is not a number with an optional prefix.
+func idNumber(id string) int {
+	g := reNumberedID.FindStringSubmatch(strings.TrimSpace(id))
+	if g == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(g[2])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// layoutPattern converts a time layout into a regular expression.
+func layoutPattern(layout string) string {
+	tokens := []string{"2006", `\d{4}`, "01", `\d{2}`, "02", `\d{2}`, "15", `\d{2}`, "04", `\d{2}`, "05", `\d{2}`}
+	var b strings.Builder
+next:
+	for i := 0; i < len(layout); {
+		for t := 0; t < len(tokens); t += 2 {
+			if strings.HasPrefix(layout[i:], tokens[t]) {
+				b.WriteString(tokens[t+1])
+				i += len(tokens[t])
+				continue next
+			}
+		}
+		b.WriteString(regexp.QuoteMeta(layout[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID: 48 bits of milliseconds and 80 random bits in
+// Crockford base32, so IDs sort by creation time.
+func newULID(t time.Time) string {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	rand.Read(b[6:])
+
+	// 26 characters of 5 bits hold 130 bits; the first two are zero.
+	var out [26]byte
+	for i := range out {
+		v := 0
+		for j := 0; j < 5; j++ {
+			v <<= 1
+			if bit := i*5 + j - 2; bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockford[v]
+	}
+	return string(out[:])
+}
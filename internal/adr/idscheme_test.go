@@ -0,0 +1,137 @@
+package adr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestIDSchemeFileID verifies which file names each scheme recognizes and
+// the canonical IDs it reads from them.
+func TestIDSchemeFileID(t *testing.T) {
+	tests := []struct {
+		scheme IDScheme
+		name   string
+		want   string // "" when the name is not an ADR
+	}{
+		{IDScheme{}, "0007-use-go.md", "0007"},
+		{IDScheme{}, "1234567-big.md", "1234567"},
+		{IDScheme{}, "README.md", ""},
+		{IDScheme{Width: 3}, "7-use-go.md", "007"},
+		{IDScheme{Prefix: "SEC-"}, "sec-12-use-go.md", "SEC-0012"},
+		{IDScheme{Prefix: "SEC-"}, "0012-use-go.md", ""},
+		{IDScheme{Kind: IDDate}, "2026-10-16-use-go.md", "2026-10-16"},
+		{IDScheme{Kind: IDDate}, "2026-10-16-2-2fa-rollout.md", "2026-10-16-2"},
+		{IDScheme{Kind: IDDate}, "2026-10-16-2fa-rollout.md", "2026-10-16"},
+		{IDScheme{Kind: IDDate}, "0001-use-go.md", ""},
+		{IDScheme{Kind: IDTimestamp}, "20261016153000-use-go.md", "20261016153000"},
+		{IDScheme{Kind: IDULID}, "01ja2z3x4y5w6v7t8s9r0qpnmk-use-go.md", "01JA2Z3X4Y5W6V7T8S9R0QPNMK"},
+	}
+	for _, tt := range tests {
+		got, ok := tt.scheme.matcher().fileID(tt.name)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%+v fileID(%s) = %q, %v, want %q", tt.scheme, tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+// TestIDKey verifies the ID forms that refer to the same ADR.
+func TestIDKey(t *testing.T) {
+	for _, same := range [][2]string{
+		{"7", "0007"}, {"ADR-0007", "7"}, {"SEC-7", "sec-0007"}, {"SEC_7", "SEC-0007"},
+		{"01ja2z3x4y5w6v7t8s9r0qpnmk", "01JA2Z3X4Y5W6V7T8S9R0QPNMK"},
+	} {
+		if idKey(same[0]) != idKey(same[1]) {
+			t.Errorf("idKey(%q) = %q, idKey(%q) = %q; want equal", same[0], idKey(same[0]), same[1], idKey(same[1]))
+		}
+	}
+	if idKey("SEC-7") == idKey("OPS-7") || idKey("SEC-7") == idKey("7") {
+		t.Error("prefixed IDs should only match their own prefix")
+	}
+}
+
+// TestIDSchemeNew verifies new ADRs follow the configured scheme and are
+// scanned back with it.
+func TestIDSchemeNew(t *testing.T) {
+	now := time.Now().Format("2006-01-02")
+	tests := []struct {
+		scheme IDScheme
+		want   []string
+	}{
+		{IDScheme{Prefix: "SEC-", Width: 3}, []string{"SEC-001", "SEC-002"}},
+		{IDScheme{Kind: IDDate}, []string{now, now + "-2"}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		m := Manager{Dir: dir, Config: Config{ID: tt.scheme}}
+		for _, title := range []string{"Use Go", "Use Rust"} {
+			if _, err := m.WriteNewADR(title, NewOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		entries, err := m.Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || entries[0].ID != tt.want[0] || entries[1].ID != tt.want[1] {
+			t.Errorf("%+v: entries %+v, want IDs %v", tt.scheme, entries, tt.want)
+			continue
+		}
+		if e, ok := Lookup(entries, tt.want[1]); !ok || e.Title != "Use Rust" {
+			t.Errorf("Lookup(%s) = %+v, %v", tt.want[1], e, ok)
+		}
+		content, _ := os.ReadFile(filepath.Join(dir, entries[1].File))
+		if !regexp.MustCompile(`(?m)^# ADR ` + regexp.QuoteMeta(tt.want[1]) + `: Use Rust$`).Match(content) {
+			t.Errorf("heading not written with the new ID:\n%s", content)
+		}
+		if vs, err := Lint(dir, LintConfig{IDs: tt.scheme, Rules: map[string]Severity{"empty-section": SeverityOff}}); err != nil || len(vs) != 0 {
+			t.Errorf("%+v: lint = %v, %v", tt.scheme, vs, err)
+		}
+	}
+
+	if e, ok := Lookup([]Entry{{Number: 7, ID: "SEC-0007"}}, "7"); !ok || e.ID != "SEC-0007" {
+		t.Error("a bare number should find the only prefixed ID with that number")
+	}
+	if id := newULID(time.UnixMilli(1)); !regexp.MustCompile(`^000000000[0-9A-HJKMNP-TV-Z]{17}$`).MatchString(id) || id[9] != '1' {
+		t.Errorf("newULID = %s", id)
+	}
+	if err := (IDScheme{Kind: IDTimestamp, Format: "Jan 2"}).Validate(); err == nil {
+		t.Error("a layout with month names should be rejected")
+	}
+	if err := (IDScheme{Width: 19}).Validate(); err == nil || !strings.Contains(err.Error(), "0 for the default") {
+		t.Errorf("width 19: %v", err)
+	}
+	if err := (IDScheme{}).Validate(); err != nil {
+		t.Errorf("width 0 should be accepted as the default: %v", err)
+	}
+}
+
+// TestScanLeadingZeroIDs verifies unquoted IDs such as 0010 keep their
+// decimal value rather than being read as YAML octal numbers.
+func TestScanLeadingZeroIDs(t *testing.T) {
+	dir := t.TempDir()
+	for n := 8; n <= 12; n++ {
+		adr := fmt.Sprintf("---\nid: %04d\ntitle: \"ADR %d\"\nstatus: \"Accepted\"\ndate: \"2025-01-15\"\n---\n\n# ADR %04d: ADR %d\n", n, n, n, n)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%04d-adr-%d.md", n, n)), []byte(adr), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(entries))
+	}
+	for i, e := range entries {
+		n := i + 8
+		if e.Number != n || e.ID != fmt.Sprintf("%04d", n) {
+			t.Errorf("%s: got ID %s (number %d), want %04d", e.File, e.ID, e.Number, n)
+		}
+	}
+}
@@ -1,125 +1,522 @@
 package adr
 
 import (
+	"bytes"
 	"embed"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 	"text/template"
 )
 
-//go:embed templates/index.md
+//go:embed templates/index.md templates/table.md
 var indexTemplate embed.FS
 
 type Entry struct {
-	Number int
-	ID     string // zero-padded string (e.g., 0001)
+	Number int    // sequence number; 0 for date, timestamp and ULID IDs
+	ID     string // formatted by the ID scheme (e.g., 0001 or SEC-0001)
 	Title  string
 	Status string
 	Date   string
-	File   string // relative path/filename
+	File   string // path relative to the ADR directory, with forward slashes
+	// Category is the subdirectory holding the ADR, e.g. "platform" for
+	// platform/0003-use-grpc.md; empty at the top level.
+	Category string
+	// Source is the name of the source directory the ADR was read from by
+	// ScanSources; empty for Scan.
+	Source string
+	// Err is the problem found reading the ADR, such as unreadable
+	// frontmatter; the other fields are filled in as far as possible.
+	Err error
+	Relations
+	Extra Fields // custom frontmatter fields
 }
 
+// Scan reads the ADRs in dir, whose IDs follow the default scheme.
 func Scan(dir string) ([]Entry, error) {
+	return scanDir(dir, IDScheme{})
+}
+
+// Scan reads the ADRs in the manager's directory using the configured ID
+// scheme.
+func (m Manager) Scan() ([]Entry, error) {
+	if err := m.checkDir(); err != nil {
+		return nil, err
+	}
+	return scanDir(m.Dir, m.Config.ID)
+}
+
+func scanDir(dir string, scheme IDScheme) ([]Entry, error) {
 	ents := []Entry{}
-	items, err := os.ReadDir(dir)
+	files, err := findADRs(dir, scheme)
 	if err != nil {
 		return nil, err
 	}
-	for _, it := range items {
-		name := it.Name()
-		// Skip non-markdown files and directories
-		if it.IsDir() || !strings.HasSuffix(name, ".md") {
-			continue
-		}
-		// Only include ADR files that start with a numeric prefix, e.g. 0001-some-decision.md
-		// This avoids picking up README.md, template.md, or other non-ADR markdown files.
-		n, hasNum := parseLeadingNumber(name)
-		if !hasNum {
-			continue
-		}
-		path := filepath.Join(dir, name)
+	ids := scheme.matcher()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.File))
 		meta, err := ParseADR(path)
-		if err != nil {
+		var fe *FrontmatterError
+		if err != nil && !errors.As(err, &fe) {
 			// Best-effort: attempt to keep going, but include a minimal entry
-			ents = append(ents, Entry{Number: n, ID: fmt.Sprintf("%04d", n), Title: name, Status: "", Date: "", File: name})
+			id, n := ids.entryID("", f.ID)
+			ents = append(ents, Entry{Number: n, ID: id, Title: f.Name, File: f.File, Category: f.Category, Err: err})
 			continue
 		}
-		// If parser succeeded but didn't capture a Number, fall back to numeric prefix
-		if meta.Number == 0 && hasNum {
-			meta.Number = n
-		}
+		// The ID written in the file wins; fall back to the file name
+		id, n := ids.entryID(meta.ID, f.ID)
 		// Ensure we have some title (parser may have failed to derive one)
 		if meta.Title == "" {
-			meta.Title = name
+			meta.Title = f.Name
 		}
 		ents = append(ents, Entry{
-			Number: meta.Number,
-			ID:     fmt.Sprintf("%04d", meta.Number),
-			Title:  meta.Title,
-			Status: meta.Status,
-			Date:   meta.Date,
-			File:   name,
+			Number:   n,
+			ID:       id,
+			Title:    meta.Title,
+			Status:   meta.Status,
+			Date:     meta.Date,
+			File:     f.File,
+			Category: f.Category,
+			Err:      err,
+
+			Relations: meta.Relations,
+			Extra:     meta.Extra,
 		})
 	}
-	// sort by Number
-	sort.Slice(ents, func(i, j int) bool { return ents[i].Number < ents[j].Number })
+	sort.SliceStable(ents, func(i, j int) bool { return entryLess(ents[i], ents[j]) })
 	return ents, nil
 }
 
+// bundleFiles are the documents of a directory-form ADR such as
+// 0007-event-bus/README.md, in order of preference.
+var bundleFiles = []string{"README.md", "index.md", "README.adoc", "index.adoc", "README.rst", "index.rst"}
+
+// adrFile is an ADR found under the ADR directory.
+type adrFile struct {
+	File     string // path relative to the ADR directory, with forward slashes
+	Name     string // the file or bundle directory name starting with the ID
+	ID       string // canonical ID from Name
+	Rest     string // Name after the ID
+	Category string // subdirectory holding the ADR; empty at the top level
+	Bundle   bool   // the ADR is a directory with assets
+}
+
+// findADRs walks dir for ADRs: markdown, AsciiDoc and reStructuredText
+// files whose names start with an ID, and directories whose names start
+// with an ID and contain one of bundleFiles. Other directories are
+// categories and are searched in turn, except hidden ones and the drafts
+// directory.
+func findADRs(dir string, scheme IDScheme) ([]adrFile, error) {
+	ids := scheme.matcher()
+	var out []adrFile
+	var walk func(category string) error
+	walk = func(category string) error {
+		items, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(category)))
+		if err != nil {
+			return err
+		}
+		for _, it := range items {
+			name := it.Name()
+			rel := path.Join(category, name)
+			if it.IsDir() {
+				if strings.HasPrefix(name, ".") || (category == "" && name == draftsDir) {
+					continue
+				}
+				// a bundle is named like an ADR file, so a category such as
+				// 2024 is not taken for ADR 2024
+				if id, rest, ok := ids.splitFile(name); ok && strings.HasPrefix(rest, "-") {
+					if doc := bundleFile(filepath.Join(dir, filepath.FromSlash(rel))); doc != "" {
+						out = append(out, adrFile{File: path.Join(rel, doc), Name: name, ID: id, Rest: rest, Category: category, Bundle: true})
+						continue
+					}
+				}
+				if err := walk(rel); err != nil {
+					return err
+				}
+				continue
+			}
+			// Only include ADR files that start with an ID, e.g. 0001-some-decision.md
+			// This avoids picking up README.md, template.md, or other non-ADR markdown files.
+			if _, ok := markupOf(name); !ok {
+				continue
+			}
+			if id, rest, ok := ids.splitFile(name); ok {
+				out = append(out, adrFile{File: rel, Name: name, ID: id, Rest: rest, Category: category})
+			}
+		}
+		return nil
+	}
+	return out, walk("")
+}
+
+// bundleFile returns the name of the ADR document in the directory dir, or
+// "" when it has none.
+func bundleFile(dir string) string {
+	for _, name := range bundleFiles {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err == nil && fi.Mode().IsRegular() {
+			return name
+		}
+	}
+	return ""
+}
+
+// entryLess orders entries by number, then by ID, which sorts date,
+// timestamp and ULID IDs by age.
+func entryLess(a, b Entry) bool {
+	if a.Number != b.Number {
+		return a.Number < b.Number
+	}
+	return a.ID < b.ID
+}
+
+// Lookup returns the entry whose ID matches id ("7", "0007" and "ADR-0007"
+// are equivalent). A bare number also finds a prefixed ID such as SEC-0007
+// when exactly one entry has that number.
+func Lookup(entries []Entry, id string) (Entry, bool) {
+	key := idKey(id)
+	for _, e := range entries {
+		if idKey(e.ID) == key {
+			return e, true
+		}
+	}
+	if n := idNumber(id); n != 0 && reNumberedID.FindStringSubmatch(strings.TrimSpace(id))[1] == "" {
+		var found []Entry
+		for _, e := range entries {
+			if e.Number == n {
+				found = append(found, e)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], true
+		}
+	}
+	return Entry{}, false
+}
+
 type IndexData struct {
 	Entries     []Entry
+	Columns     []Column
 	ProjectName string
 	ProjectURL  string
+	Graph       string // Mermaid flowchart source, empty when disabled
+	LinkBase    string // ADR directory relative to the output file
+	// SourceLinks maps source names to their LinkBase, for entries read by
+	// ScanSources.
+	SourceLinks map[string]string
+
+	// Source names the source of the entries when the index is grouped;
+	// Sources holds one group per source when there is more than one.
+	Source  string
+	Sources []IndexData
+	all     []Entry // every entry, for resolving references across groups
 }
 
-func WriteIndex(out string, entries []Entry, projectName, projectURL string) error {
-	// Escape pipe characters in entries
-	for i := range entries {
-		entries[i].Title = escapePipes(entries[i].Title)
-		entries[i].Status = escapePipes(entries[i].Status)
-		entries[i].Date = escapePipes(entries[i].Date)
+// DefaultColumns are the index table columns used when none are configured.
+var DefaultColumns = []string{"id", "title", "status", "date"}
+
+// Column is a column of the index table. Keys other than the built-in
+// id, number, title, status, date, file, category, source and relation
+// kinds refer to custom frontmatter fields.
+type Column struct {
+	Key    string
+	Header string
+	Align  string // markdown alignment cell, e.g. ":---"
+}
+
+var builtinColumns = map[string]Column{
+	"id":       {Key: "id", Header: "ID", Align: "---:"},
+	"number":   {Key: "number", Header: "Number", Align: "---:"},
+	"title":    {Key: "title", Header: "Title", Align: ":------"},
+	"status":   {Key: "status", Header: "Status", Align: ":------:"},
+	"date":     {Key: "date", Header: "Date", Align: ":-----:"},
+	"file":     {Key: "file", Header: "File", Align: ":---"},
+	"category": {Key: "category", Header: "Category", Align: ":---"},
+	"source":   {Key: "source", Header: "Source", Align: ":---"},
+}
+
+// NewColumns builds table columns from keys such as "id,title,deciders".
+func NewColumns(keys []string) []Column {
+	if len(keys) == 0 {
+		keys = DefaultColumns
+	}
+	cols := make([]Column, 0, len(keys))
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		if c, ok := builtinColumns[strings.ToLower(k)]; ok {
+			cols = append(cols, c)
+			continue
+		}
+		header := strings.ReplaceAll(strings.ReplaceAll(k, "_", " "), "-", " ")
+		if header != "" {
+			header = strings.ToUpper(header[:1]) + header[1:]
+		}
+		cols = append(cols, Column{Key: k, Header: header, Align: ":---"})
 	}
+	return cols
+}
 
-	data := IndexData{
-		Entries:     entries,
-		ProjectName: projectName,
-		ProjectURL:  projectURL,
+// Field returns the text of a built-in field, relation or custom frontmatter
+// field by name. Lists are joined with ", ".
+func (e Entry) Field(key string) string {
+	lower := strings.ToLower(key)
+	switch lower {
+	case "id":
+		return e.ID
+	case "number":
+		return fmt.Sprint(e.Number)
+	case "title":
+		return e.Title
+	case "status":
+		return e.Status
+	case "date":
+		return e.Date
+	case "file":
+		return e.File
+	case "category":
+		return e.Category
+	case "source":
+		return e.Source
+	}
+	for _, kind := range RelationKinds {
+		if kind == lower {
+			return strings.Join(e.Get(kind), ", ")
+		}
 	}
+	if !e.Extra.Has(key) {
+		// like the fields above, custom keys match in any case
+		for _, k := range e.Extra.Keys() {
+			if strings.ToLower(k) == lower {
+				key = k
+				break
+			}
+		}
+	}
+	return FormatValue(e.Extra.Get(key), ", ")
+}
 
-	// Load and parse template
-	tmplContent, err := indexTemplate.ReadFile("templates/index.md")
-	if err != nil {
-		return fmt.Errorf("failed to read index template: %w", err)
+// Cell renders an entry's value for a table column. Built-in fields are
+// escaped when the index is rendered; custom fields are escaped here.
+func (d IndexData) Cell(e Entry, key string) string {
+	if _, ok := builtinColumns[strings.ToLower(key)]; ok {
+		return e.Field(key)
 	}
+	return strings.ReplaceAll(escapePipes(e.Field(key)), "\n", " ")
+}
 
-	tmpl, err := template.New("index").Parse(string(tmplContent))
+// Href returns the link to an entry's file, relative to the index output.
+func (d IndexData) Href(e Entry) string {
+	base := d.LinkBase
+	if b, ok := d.SourceLinks[e.Source]; ok && e.Source != "" {
+		base = b
+	}
+	p := path.Join(filepath.ToSlash(base), filepath.ToSlash(e.File))
+	if strings.HasPrefix(p, "../") || path.IsAbs(p) {
+		return p
+	}
+	return "./" + p
+}
+
+// LinkBase returns the ADR directory relative to the directory of the output
+// file out, for use as IndexOptions.LinkBase.
+func LinkBase(out, dir string) string {
+	outDir, err := filepath.Abs(filepath.Dir(out))
+	if err != nil {
+		return dir
+	}
+	adrDir, err := filepath.Abs(dir)
 	if err != nil {
-		return fmt.Errorf("failed to parse index template: %w", err)
+		return dir
+	}
+	rel, err := filepath.Rel(outDir, adrDir)
+	if err != nil {
+		return adrDir
+	}
+	return rel
+}
+
+// Links renders the referenced ADRs as markdown links, for use in templates.
+func (d IndexData) Links(ids IDList) string {
+	all := d.all
+	if all == nil {
+		all = d.Entries
+	}
+	links := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if e, ok := Lookup(all, id); ok {
+			links = append(links, fmt.Sprintf("[%s](%s)", e.ID, d.Href(e)))
+		} else {
+			links = append(links, escapePipes(id))
+		}
+	}
+	return strings.Join(links, ", ")
+}
+
+// IndexOptions controls index rendering.
+type IndexOptions struct {
+	ProjectName string
+	ProjectURL  string
+	Graph       bool     // embed a Mermaid decision graph below the table
+	Format      string   // markdown (default), json, yaml, csv or jsonl
+	Columns     []string // table columns; defaults to DefaultColumns
+	Template    string   // path to a custom index template; empty for the built-in one
+	LinkBase    string   // ADR directory relative to the output file; see LinkBase
+	// SourceLinks maps source names to their directory relative to the
+	// output file, for entries read by ScanSources.
+	SourceLinks map[string]string
+	// Regions configures named marker regions for InjectIndex.
+	Regions map[string]IndexRegion
+}
+
+func WriteIndex(out string, entries []Entry, projectName, projectURL string) error {
+	return WriteIndexOptions(out, entries, IndexOptions{ProjectName: projectName, ProjectURL: projectURL})
+}
+
+// WriteIndexOptions renders the index to the file at out. The file is
+// replaced atomically, and left untouched when its content is already current.
+func WriteIndexOptions(out string, entries []Entry, opt IndexOptions) error {
+	var buf bytes.Buffer
+	if err := RenderIndex(&buf, entries, opt); err != nil {
+		return err
 	}
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
 		return err
 	}
+	_, err := writeFileIfChanged(out, buf.Bytes())
+	return err
+}
+
+// CheckIndex renders the index in memory and compares it with the file at
+// out. It returns a unified diff from the file on disk to the expected
+// content, or an empty string when the index is up to date.
+func CheckIndex(out string, entries []Entry, opt IndexOptions) (string, error) {
+	var buf bytes.Buffer
+	if err := RenderIndex(&buf, entries, opt); err != nil {
+		return "", err
+	}
+	current, err := os.ReadFile(out)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return UnifiedDiff("a/"+filepath.ToSlash(out), "b/"+filepath.ToSlash(out), current, buf.Bytes()), nil
+}
+
+// RenderIndex writes the index for entries to w: the markdown template by
+// default, or one of the Export data formats.
+func RenderIndex(w io.Writer, entries []Entry, opt IndexOptions) error {
+	if opt.Format != "" && !strings.EqualFold(opt.Format, FormatMarkdown) {
+		return Export(w, entries, opt.Format)
+	}
+
+	data, err := newIndexData(entries, opt)
+	if err != nil {
+		return err
+	}
 
-	// Create output file
-	f, err := os.Create(out)
+	tmpl, err := loadIndexTemplate(opt.Template)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
 	// Execute template
-	if err := tmpl.Execute(f, data); err != nil {
+	if err := tmpl.Execute(w, data); err != nil {
 		return fmt.Errorf("failed to execute index template: %w", err)
 	}
 
 	return nil
 }
 
+// newIndexData prepares the template data for entries, escaping the fields
+// that are written into table cells.
+func newIndexData(entries []Entry, opt IndexOptions) (IndexData, error) {
+	data := IndexData{
+		Columns:     NewColumns(opt.Columns),
+		LinkBase:    opt.LinkBase,
+		SourceLinks: opt.SourceLinks,
+		ProjectName: opt.ProjectName,
+		ProjectURL:  opt.ProjectURL,
+	}
+
+	if opt.Graph && len(entries) > 0 {
+		var g strings.Builder
+		if err := WriteMermaid(&g, entries); err != nil {
+			return IndexData{}, err
+		}
+		data.Graph = g.String()
+	}
+
+	// Escape pipe characters in entries
+	data.Entries = make([]Entry, len(entries))
+	for i, e := range entries {
+		e.Title = escapePipes(e.Title)
+		e.Status = escapePipes(e.Status)
+		e.Date = escapePipes(e.Date)
+		data.Entries[i] = e
+	}
+	data.Sources = data.bySource()
+
+	return data, nil
+}
+
+// loadIndexTemplate parses the built-in or a custom index template. The
+// "table" template is always defined, so custom templates can reuse it with
+// {{template "table" .}}.
+func loadIndexTemplate(custom string) (*template.Template, error) {
+	tableContent, err := indexTemplate.ReadFile("templates/table.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index template: %w", err)
+	}
+	var tmplContent []byte
+	if custom != "" {
+		tmplContent, err = os.ReadFile(custom)
+	} else {
+		tmplContent, err = indexTemplate.ReadFile("templates/index.md")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index template: %w", err)
+	}
+
+	tmpl, err := template.New("index").Funcs(TemplateFuncs()).Parse(string(tableContent))
+	if err == nil {
+		tmpl, err = tmpl.Parse(string(tmplContent))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index template: %w", err)
+	}
+	return tmpl, nil
+}
+
 func escapePipes(s string) string {
 	return strings.ReplaceAll(s, "|", "\\|")
 }
+
+// bySource splits the entries into one IndexData per source, in the order
+// the sources first appear, or returns nil when there is only one source.
+func (d IndexData) bySource() []IndexData {
+	var groups []IndexData
+	at := map[string]int{}
+	for _, e := range d.Entries {
+		i, ok := at[e.Source]
+		if !ok {
+			i = len(groups)
+			at[e.Source] = i
+			g := d
+			g.Source, g.Entries, g.Sources, g.all = e.Source, nil, nil, d.Entries
+			groups = append(groups, g)
+		}
+		groups[i].Entries = append(groups[i].Entries, e)
+	}
+	if len(groups) < 2 {
+		return nil
+	}
+	return groups
+}
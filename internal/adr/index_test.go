@@ -0,0 +1,182 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func indexFixture() []Entry {
+	return []Entry{
+		{Number: 1, ID: "0001", Title: "First Decision", Status: "Accepted", Date: "2025-01-15", File: "0001-first.md"},
+		{Number: 2, ID: "0002", Title: "Second Decision", Status: "Proposed", Date: "2025-01-16", File: "0002-second.md"},
+	}
+}
+
+// TestCheckIndex verifies stale and current indexes are detected, and that
+// a diff is produced for stale ones.
+func TestCheckIndex(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "index.md")
+	entries := indexFixture()
+
+	diff, err := CheckIndex(out, entries, IndexOptions{})
+	if err != nil {
+		t.Fatalf("CheckIndex failed: %v", err)
+	}
+	if diff == "" {
+		t.Fatal("missing index should be reported as stale")
+	}
+
+	if err := WriteIndexOptions(out, entries, IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err = CheckIndex(out, entries, IndexOptions{}); err != nil || diff != "" {
+		t.Fatalf("freshly written index should be current, got diff %q, err %v", diff, err)
+	}
+
+	entries[1].Status = "Accepted"
+	diff, err = CheckIndex(out, entries, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"--- a/" + filepath.ToSlash(out),
+		"-| 0002 | [Second Decision](./0002-second.md) | Proposed | 2025-01-16 |",
+		"+| 0002 | [Second Decision](./0002-second.md) | Accepted | 2025-01-16 |",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+}
+
+// TestWriteIndexSkipsUnchanged verifies an up-to-date index is not rewritten
+// and that no temporary files are left behind.
+func TestWriteIndexSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "index.md")
+	if err := WriteIndexOptions(out, indexFixture(), IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(out, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteIndexOptions(out, indexFixture(), IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(old) {
+		t.Error("unchanged index should not be rewritten")
+	}
+
+	if err := WriteIndexOptions(out, indexFixture()[:1], IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "Second Decision") {
+		t.Error("changed index should be rewritten")
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected only index.md in %s, found %d files", dir, len(files))
+	}
+	// the output directory is created as needed
+	nested := filepath.Join(dir, "site", "adr", "index.md")
+	if err := WriteIndexOptions(nested, indexFixture(), IndexOptions{}); err != nil {
+		t.Errorf("index in a new directory: %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := UnifiedDiff("a", "b", []byte(a), []byte(b)); got != want {
+		t.Errorf("UnifiedDiff:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := UnifiedDiff("a", "b", []byte(a), []byte(a)); got != "" {
+		t.Errorf("equal input should produce no diff, got %q", got)
+	}
+
+	// Only the trailing newline differs.
+	want = "--- a\n+++ b\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+12\n\\ No newline at end of file\n"
+	if got := UnifiedDiff("a", "b", []byte(a), []byte(strings.TrimSuffix(a, "\n"))); got != want {
+		t.Errorf("UnifiedDiff without final newline:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestIndexColumns verifies custom frontmatter fields can be selected as
+// index columns and that the default table layout is unchanged.
+func TestIndexColumns(t *testing.T) {
+	entries := indexFixture()
+	entries[0].Extra.Set("deciders", []any{"alice", "bob"})
+	entries[0].Extra.Set("jira", "ABC|12")
+
+	var buf strings.Builder
+	if err := RenderIndex(&buf, entries, IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "| ID | Title | Status | Date |\n|---:|:------|:------:|:-----:|\n| 0001 | [First Decision](./0001-first.md) | Accepted | 2025-01-15 |\n") {
+		t.Errorf("default table layout changed:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	opt := IndexOptions{Columns: []string{"id", "title", "deciders", "jira", "date"}}
+	if err := RenderIndex(&buf, entries, opt); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| ID | Title | Deciders | Jira | Date |\n|---:|:------|:---|:---|:-----:|\n",
+		"| 0001 | [First Decision](./0001-first.md) | alice, bob | ABC\\|12 | 2025-01-15 |\n",
+		"| 0002 | [Second Decision](./0002-second.md) |  |  | 2025-01-16 |\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("index missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if got := entries[0].Field("deciders"); got != "alice, bob" {
+		t.Errorf("Field(deciders): got %q", got)
+	}
+	if got := entries[1].Field("missing"); got != "" {
+		t.Errorf("Field(missing): got %q", got)
+	}
+	entries[1].Supersedes = IDList{"1"}
+	for _, key := range []string{"Supersedes", "SUPERSEDES"} {
+		if got := entries[1].Field(key); got != "1" {
+			t.Errorf("Field(%s): got %q", key, got)
+		}
+	}
+	if got := entries[0].Field("Deciders"); got != "alice, bob" {
+		t.Errorf("Field(Deciders): got %q", got)
+	}
+}
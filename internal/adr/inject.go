@@ -0,0 +1,241 @@
+package adr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Index markers delimit the regions of a markdown file that InjectIndex
+// rewrites. Attributes on the start marker select the region name and may
+// override the status filter and columns:
+//
+//	<!-- adrctl:index:start name=accepted status=Accepted columns=id,title,date -->
+//	<!-- adrctl:index:end name=accepted -->
+//
+// Regions without a name are unnamed; an end marker without a name closes the
+// region that is currently open.
+var reIndexMarker = regexp.MustCompile(`^\s*<!--\s*adrctl:index:(start|end)\b(.*?)-->\s*$`)
+
+// indexMarker is a parsed start or end marker.
+type indexMarker struct {
+	Start bool
+	Name  string
+	Attrs map[string]string
+}
+
+func parseIndexMarker(line string) (indexMarker, bool, error) {
+	g := reIndexMarker.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if g == nil {
+		return indexMarker{}, false, nil
+	}
+	m := indexMarker{Start: g[1] == "start", Attrs: map[string]string{}}
+	for _, field := range strings.Fields(g[2]) {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok {
+			return m, true, fmt.Errorf("invalid marker attribute %q (want key=value)", field)
+		}
+		switch k {
+		case "name", "status", "columns":
+		default:
+			return m, true, fmt.Errorf("unknown marker attribute %q (want name, status or columns)", k)
+		}
+		m.Attrs[k] = strings.Trim(v, `"'`)
+	}
+	m.Name = m.Attrs["name"]
+	return m, true, nil
+}
+
+// InjectIndex replaces the content between each pair of adrctl:index
+// markers in content with an ADR table and returns the result. Lines outside
+// the markers, and the marker lines themselves, are left untouched. Markers
+// inside fenced code blocks are ignored.
+//
+// A region's entries and columns come from opt.Regions[name], overridden by
+// the status= and columns= attributes of its start marker.
+func InjectIndex(content []byte, entries []Entry, opt IndexOptions) ([]byte, error) {
+	tmpl, err := loadIndexTemplate(opt.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	newline := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+
+	var out bytes.Buffer
+	var open *indexMarker
+	openLine, regions := 0, 0
+	fence := ""
+	for i, line := range lines {
+		text := string(line)
+		if f := codeFence(text); f != "" {
+			switch {
+			case fence == "":
+				fence = f
+			case strings.HasPrefix(f, fence):
+				fence = ""
+			}
+		}
+		m, ok, err := parseIndexMarker(text)
+		if fence != "" || !ok {
+			if open == nil {
+				out.Write(line)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch {
+		case m.Start && open != nil:
+			return nil, fmt.Errorf("line %d: index region %s starts before the region opened on line %d ends", i+1, regionName(m.Name), openLine)
+		case m.Start:
+			open, openLine = &m, i+1
+			out.Write(line)
+		case open == nil:
+			return nil, fmt.Errorf("line %d: index end marker without a start marker", i+1)
+		case m.Name != "" && m.Name != open.Name:
+			return nil, fmt.Errorf("line %d: end marker for %s closes region %s opened on line %d", i+1, regionName(m.Name), regionName(open.Name), openLine)
+		default:
+			table, err := renderRegion(tmpl, entries, opt, *open)
+			if err != nil {
+				return nil, fmt.Errorf("index region %s: %w", regionName(open.Name), err)
+			}
+			if newline != "\n" {
+				table = strings.ReplaceAll(table, "\n", newline)
+			}
+			out.WriteString(table)
+			out.Write(line)
+			open = nil
+			regions++
+		}
+	}
+	if open != nil {
+		return nil, fmt.Errorf("line %d: index region %s has no end marker", openLine, regionName(open.Name))
+	}
+	if regions == 0 {
+		return nil, errors.New("no <!-- adrctl:index:start --> marker found")
+	}
+	return out.Bytes(), nil
+}
+
+// renderRegion renders the "table" template for the entries selected by a
+// region.
+func renderRegion(tmpl *template.Template, entries []Entry, opt IndexOptions, m indexMarker) (string, error) {
+	region := opt.Regions[m.Name]
+	if v, ok := m.Attrs["status"]; ok {
+		region.Status = splitList(v)
+	}
+	if v, ok := m.Attrs["columns"]; ok {
+		region.Columns = splitList(v)
+	}
+	if len(region.Columns) > 0 {
+		opt.Columns = region.Columns
+	}
+	opt.Graph = false
+
+	data, err := newIndexData(filterStatus(entries, region.Status), opt)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := tmpl.ExecuteTemplate(&buf, "table", data); err != nil {
+		return "", fmt.Errorf("failed to execute index template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// filterStatus keeps the entries whose status starts with one of statuses,
+// ignoring case, so "Superseded" matches "Superseded by ADR 0003". An empty
+// list keeps every entry.
+func filterStatus(entries []Entry, statuses []string) []Entry {
+	if len(statuses) == 0 {
+		return entries
+	}
+	var out []Entry
+	for _, e := range entries {
+		status := strings.ToLower(strings.TrimSpace(e.Status))
+		for _, s := range statuses {
+			if strings.HasPrefix(status, strings.ToLower(s)) {
+				out = append(out, e)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func regionName(name string) string {
+	if name == "" {
+		return "(unnamed)"
+	}
+	return strconv.Quote(name)
+}
+
+// codeFence returns the fence (``` or ~~~, possibly longer) that opens or
+// closes a fenced code block on line, or "".
+func codeFence(line string) string {
+	t := strings.TrimLeft(line, " ")
+	if len(line)-len(t) > 3 || len(t) < 3 || (t[0] != '`' && t[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(t) && t[n] == t[0] {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return t[:n]
+}
+
+// WriteIndexInto injects the ADR table into the marker regions of the
+// markdown file at path. The file is only rewritten when its content changes.
+func WriteIndexInto(path string, entries []Entry, opt IndexOptions) error {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	updated, err := InjectIndex(current, entries, opt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	_, err = writeFileIfChanged(path, updated)
+	return err
+}
+
+// CheckIndexInto is the --check counterpart of WriteIndexInto. It returns a
+// unified diff of the pending changes, or an empty string when the marker
+// regions are up to date.
+func CheckIndexInto(path string, entries []Entry, opt IndexOptions) (string, error) {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	updated, err := InjectIndex(current, entries, opt)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	name := filepath.ToSlash(path)
+	return UnifiedDiff("a/"+name, "b/"+name, current, updated), nil
+}
@@ -0,0 +1,90 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInjectIndex verifies only the marker regions are replaced, that named
+// regions apply their own status filter and columns, and that markers in
+// code blocks are left alone.
+func TestInjectIndex(t *testing.T) {
+	in := "# Project\n\nIntro.\n\n" +
+		"<!-- adrctl:index:start name=accepted -->\nstale\n<!-- adrctl:index:end name=accepted -->\n\n" +
+		"<!-- adrctl:index:start status=proposed columns=id,title -->\n<!-- adrctl:index:end -->\n\n" +
+		"```\n<!-- adrctl:index:start -->\n```\nOutro.\n"
+	opt := IndexOptions{Regions: map[string]IndexRegion{"accepted": {Status: []string{"Accepted"}}}}
+
+	out, err := InjectIndex([]byte(in), indexFixture(), opt)
+	if err != nil {
+		t.Fatalf("InjectIndex failed: %v", err)
+	}
+	want := "# Project\n\nIntro.\n\n" +
+		"<!-- adrctl:index:start name=accepted -->\n" +
+		"| ID | Title | Status | Date |\n|---:|:------|:------:|:-----:|\n" +
+		"| 0001 | [First Decision](./0001-first.md) | Accepted | 2025-01-15 |\n" +
+		"<!-- adrctl:index:end name=accepted -->\n\n" +
+		"<!-- adrctl:index:start status=proposed columns=id,title -->\n" +
+		"| ID | Title |\n|---:|:------|\n" +
+		"| 0002 | [Second Decision](./0002-second.md) |\n" +
+		"<!-- adrctl:index:end -->\n\n" +
+		"```\n<!-- adrctl:index:start -->\n```\nOutro.\n"
+	if string(out) != want {
+		t.Errorf("InjectIndex output:\n%s\nwant:\n%s", out, want)
+	}
+
+	again, err := InjectIndex(out, indexFixture(), opt)
+	if err != nil || string(again) != string(out) {
+		t.Errorf("InjectIndex should be idempotent, err %v:\n%s", err, again)
+	}
+}
+
+// TestInjectIndexErrors verifies malformed or missing markers are rejected.
+func TestInjectIndexErrors(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"no markers", "# Title\n", "no <!-- adrctl:index:start --> marker"},
+		{"unterminated", "<!-- adrctl:index:start -->\n", "has no end marker"},
+		{"end without start", "<!-- adrctl:index:end -->\n", "without a start marker"},
+		{"nested", "<!-- adrctl:index:start name=a -->\n<!-- adrctl:index:start name=b -->\n", "starts before"},
+		{"mismatched", "<!-- adrctl:index:start name=a -->\n<!-- adrctl:index:end name=b -->\n", "closes region"},
+		{"bad attribute", "<!-- adrctl:index:start sort=id -->\n<!-- adrctl:index:end -->\n", "unknown marker attribute"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := InjectIndex([]byte(tt.in), indexFixture(), IndexOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteIndexInto verifies the file is updated in place, links resolve
+// relative to it, and CheckIndexInto reports stale regions.
+func TestWriteIndexInto(t *testing.T) {
+	dir := t.TempDir()
+	readme := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(readme, []byte("Intro\r\n<!-- adrctl:index:start -->\r\n<!-- adrctl:index:end -->\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opt := IndexOptions{LinkBase: LinkBase(readme, filepath.Join(dir, "adr"))}
+
+	diff, err := CheckIndexInto(readme, indexFixture(), opt)
+	if err != nil || diff == "" {
+		t.Fatalf("expected a diff for a stale region, got %q, err %v", diff, err)
+	}
+	if err := WriteIndexInto(readme, indexFixture(), opt); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(readme)
+	if !strings.Contains(string(got), "| 0001 | [First Decision](./adr/0001-first.md) | Accepted | 2025-01-15 |\r\n") {
+		t.Errorf("table missing or not CRLF with ./adr links:\n%q", got)
+	}
+	if diff, err = CheckIndexInto(readme, indexFixture(), opt); err != nil || diff != "" {
+		t.Errorf("updated file should be current, got diff %q, err %v", diff, err)
+	}
+}
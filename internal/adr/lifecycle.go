@@ -0,0 +1,142 @@
+package adr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StatusDef declares one status of the ADR lifecycle.
+type StatusDef struct {
+	Name    string   `yaml:"name"`    // canonical spelling written to ADRs
+	Aliases []string `yaml:"aliases"` // other spellings that normalize to Name
+	Next    []string `yaml:"next"`    // statuses this one may transition to
+}
+
+// Lifecycle is the set of statuses an ADR may have and the allowed
+// transitions between them. The zero value means DefaultLifecycle.
+type Lifecycle struct {
+	Statuses []StatusDef `yaml:"statuses"`
+}
+
+// DefaultLifecycle returns the built-in lifecycle:
+//
+//	Draft      -> Proposed, Withdrawn
+//	Proposed   -> Accepted, Rejected, Withdrawn, Superseded
+//	Accepted   -> Deprecated, Superseded
+//	Deprecated -> Superseded
+//
+// Rejected, Withdrawn and Superseded are final.
+func DefaultLifecycle() Lifecycle {
+	return Lifecycle{Statuses: []StatusDef{
+		{Name: "Draft", Aliases: []string{"WIP"}, Next: []string{"Proposed", "Withdrawn"}},
+		{Name: "Proposed", Aliases: []string{"Open", "Pending"}, Next: []string{"Accepted", "Rejected", "Withdrawn", "Superseded"}},
+		{Name: "Accepted", Aliases: []string{"Approved", "Adopted"}, Next: []string{"Deprecated", "Superseded"}},
+		{Name: "Rejected", Aliases: []string{"Declined"}},
+		{Name: "Withdrawn", Aliases: []string{"Abandoned"}},
+		{Name: "Deprecated", Next: []string{"Superseded"}},
+		{Name: "Superseded", Aliases: []string{"Replaced"}},
+	}}
+}
+
+func (l Lifecycle) statuses() []StatusDef {
+	if len(l.Statuses) == 0 {
+		return DefaultLifecycle().Statuses
+	}
+	return l.Statuses
+}
+
+// Names returns the canonical status names in declaration order.
+func (l Lifecycle) Names() []string {
+	var names []string
+	for _, s := range l.statuses() {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// Validate reports duplicate names or aliases and transitions to statuses
+// that are not declared.
+func (l Lifecycle) Validate() error {
+	seen := map[string]string{}
+	for _, s := range l.statuses() {
+		if strings.TrimSpace(s.Name) == "" {
+			return fmt.Errorf("lifecycle: status without a name")
+		}
+		for _, spelling := range append([]string{s.Name}, s.Aliases...) {
+			key := strings.ToLower(strings.TrimSpace(spelling))
+			if other, dup := seen[key]; dup {
+				return fmt.Errorf("lifecycle: %q is used by both %s and %s", spelling, other, s.Name)
+			}
+			seen[key] = s.Name
+		}
+	}
+	for _, s := range l.statuses() {
+		for _, next := range s.Next {
+			if _, ok := l.Normalize(next); !ok {
+				return fmt.Errorf("lifecycle: %s lists unknown next status %q", s.Name, next)
+			}
+		}
+	}
+	return nil
+}
+
+// lookup finds the status a spelling belongs to. A status matches when it
+// equals the name or an alias, ignoring case, or starts with one followed by
+// a space, so "Superseded by ADR 0003" is Superseded.
+func (l Lifecycle) lookup(status string) (StatusDef, bool) {
+	s := strings.ToLower(strings.TrimSpace(status))
+	if s == "" {
+		return StatusDef{}, false
+	}
+	for _, exact := range []bool{true, false} {
+		for _, def := range l.statuses() {
+			for _, spelling := range append([]string{def.Name}, def.Aliases...) {
+				sp := strings.ToLower(spelling)
+				if s == sp || (!exact && strings.HasPrefix(s, sp+" ")) {
+					return def, true
+				}
+			}
+		}
+	}
+	return StatusDef{}, false
+}
+
+// Normalize returns the canonical name for status, accepting aliases and any
+// capitalization. It reports false for statuses the lifecycle does not know.
+func (l Lifecycle) Normalize(status string) (string, bool) {
+	def, ok := l.lookup(status)
+	return def.Name, ok
+}
+
+// CheckTransition reports whether an ADR may move from one status to
+// another. Staying in the same status is always allowed, as is setting the
+// first status of an ADR that has none.
+func (l Lifecycle) CheckTransition(from, to string) error {
+	target, ok := l.lookup(to)
+	if !ok {
+		return l.unknown(to)
+	}
+	if strings.TrimSpace(from) == "" {
+		return nil
+	}
+	current, ok := l.lookup(from)
+	if !ok {
+		return l.unknown(from)
+	}
+	if current.Name == target.Name {
+		return nil
+	}
+	for _, next := range current.Next {
+		if n, _ := l.Normalize(next); n == target.Name {
+			return nil
+		}
+	}
+	if len(current.Next) == 0 {
+		return fmt.Errorf("cannot change status from %s to %s: %s is a final status", current.Name, target.Name, current.Name)
+	}
+	return fmt.Errorf("cannot change status from %s to %s (allowed: %s)", current.Name, target.Name, strings.Join(current.Next, ", "))
+}
+
+func (l Lifecycle) unknown(status string) error {
+	return fmt.Errorf("unknown status %q (want one of %s)", status, strings.Join(l.Names(), ", "))
+}
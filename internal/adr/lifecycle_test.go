@@ -0,0 +1,105 @@
+package adr
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestLifecycleNormalize verifies aliases, capitalization and status
+// suffixes map to the canonical status names.
+func TestLifecycleNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"Accepted", "Accepted", true},
+		{"accepted", "Accepted", true},
+		{"ACCEPTED", "Accepted", true},
+		{"Approved", "Accepted", true},
+		{"  wip ", "Draft", true},
+		{"Superseded by ADR 0003", "Superseded", true},
+		{"Acceptedish", "", false},
+		{"Working", "", false},
+		{"", "", false},
+	}
+	var l Lifecycle
+	for _, tt := range tests {
+		got, ok := l.Normalize(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestLifecycleTransitions checks allowed and rejected transitions in the
+// default lifecycle.
+func TestLifecycleTransitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  string
+	}{
+		{"Proposed", "Accepted", ""},
+		{"proposed", "approved", ""},
+		{"Accepted", "Superseded", ""},
+		{"Superseded by ADR 0004", "Superseded", ""},
+		{"", "Accepted", ""},
+		{"Proposed", "Deprecated", "allowed: Accepted, Rejected, Withdrawn, Superseded"},
+		{"Rejected", "Accepted", "Rejected is a final status"},
+		{"Working", "Accepted", `unknown status "Working"`},
+		{"Accepted", "Done", `unknown status "Done"`},
+	}
+	var l Lifecycle
+	for _, tt := range tests {
+		err := l.CheckTransition(tt.from, tt.to)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s -> %s: unexpected error %v", tt.from, tt.to, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s -> %s: error = %v, want %q", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
+
+// TestLifecycleValidate rejects duplicate spellings and unknown targets.
+func TestLifecycleValidate(t *testing.T) {
+	if err := DefaultLifecycle().Validate(); err != nil {
+		t.Fatalf("default lifecycle is invalid: %v", err)
+	}
+	dup := Lifecycle{Statuses: []StatusDef{{Name: "Open"}, {Name: "Closed", Aliases: []string{"open"}}}}
+	if err := dup.Validate(); err == nil {
+		t.Error("duplicate alias should be rejected")
+	}
+	dangling := Lifecycle{Statuses: []StatusDef{{Name: "Open", Next: []string{"Done"}}}}
+	if err := dangling.Validate(); err == nil {
+		t.Error("unknown next status should be rejected")
+	}
+}
+
+// TestLifecycleEnforced verifies new normalizes statuses and supersede
+// refuses illegal transitions.
+func TestLifecycleEnforced(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+
+	path, err := m.WriteNewADR("Use Go", NewOptions{Status: "approved"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta, _ := ParseADR(path); meta.Status != "Accepted" {
+		t.Errorf("status should be normalized to Accepted, got %q", meta.Status)
+	}
+	if _, err := m.WriteNewADR("Use Rust", NewOptions{Status: "Maybe"}); err == nil {
+		t.Error("unknown status should be rejected")
+	}
+
+	if _, err := m.WriteNewADR("Use Java", NewOptions{Status: "Rejected"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Supersede("2", "Use Kotlin", NewOptions{}); err == nil || !strings.Contains(err.Error(), "final status") {
+		t.Errorf("superseding a rejected ADR should fail, got %v", err)
+	}
+	if items, _ := os.ReadDir(dir); len(items) != 2 {
+		t.Errorf("failed supersede should not create an ADR, found %d files", len(items))
+	}
+}
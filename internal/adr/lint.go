@@ -0,0 +1,441 @@
+package adr
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a lint rule or violation.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityOff     Severity = "off"
+)
+
+// LintConfig enables, disables and tunes lint rules.
+type LintConfig struct {
+	// Rules overrides the severity of individual rules by name; use "off"
+	// to disable a rule.
+	Rules map[string]Severity `yaml:"rules"`
+	// Required lists the frontmatter fields checked by missing-field.
+	// Defaults to id, title, status and date.
+	Required []string `yaml:"required"`
+	// Template is the default ADR template the completeness rules compare
+	// against. Defaults to the top-level template setting.
+	Template string `yaml:"template"`
+	// Templates lists other templates ADRs may have been created from. Each
+	// ADR is compared with the one it matches best, among these, Template
+	// and the built-in templates.
+	Templates []string `yaml:"templates"`
+	// Sections lists the sections (by canonical name, e.g. context) every
+	// ADR must contain, checked by missing-section, and must fill in,
+	// checked by empty-section. Defaults for empty-section to the sections
+	// of the ADR's template not marked optional.
+	Sections []string `yaml:"sections"`
+	// Strict lists the statuses whose ADRs have completeness warnings
+	// reported as errors. Defaults to Accepted.
+	Strict []string `yaml:"strict"`
+	// Lifecycle is the status model checked by unknown-status and
+	// noncanonical-status. It is copied from the top-level lifecycle config.
+	Lifecycle Lifecycle `yaml:"-"`
+	// IDs is the ID scheme ADR files are recognized and checked by. It is
+	// copied from the top-level id config.
+	IDs IDScheme `yaml:"-"`
+}
+
+// Violation is a single problem reported by a lint rule.
+type Violation struct {
+	Rule     string
+	Severity Severity
+	File     string
+	Line     int
+	Message  string
+
+	strict bool // escalate a warning to an error (ADR has a strict status)
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s]", v.File, v.Line, v.Severity, v.Message, v.Rule)
+}
+
+// Rule is a named lint check.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity // default severity
+
+	check func(files []*lintFile, cfg LintConfig) []Violation
+}
+
+// lintFile is an ADR file prepared once and shared by all rules.
+type lintFile struct {
+	Path   string
+	Rel    string // path relative to the ADR directory
+	Markup string // MarkupMarkdown, MarkupAsciiDoc or MarkupRST
+	FileID string // ID in the file name
+	ID     string // ID written in the file, else FileID
+	ids    idMatcher
+
+	HasFrontmatter bool
+	Frontmatter    *yaml.Node // mapping node; nil when missing or invalid
+	FrontmatterAt  int        // line of the first entry in the frontmatter
+	format         string     // FrontmatterYAML, FrontmatterTOML, FrontmatterAttributes or FrontmatterFields
+	YAMLErr        error
+	YAMLErrLine    int
+
+	Meta  Meta
+	Lines []string  // file content, for rules that inspect the body
+	Doc   *Document // nil when the frontmatter cannot be parsed
+
+	template *templateModel // template the ADR was made from; nil when not compared
+	strict   bool           // the status makes completeness warnings errors
+}
+
+// Rules returns the built-in lint rules in the order they run.
+func Rules() []Rule {
+	return []Rule{
+		{Name: "missing-frontmatter", Severity: SeverityWarning,
+			Description: "ADR has no YAML frontmatter (AsciiDoc attributes, reStructuredText fields) and relies on legacy parsing",
+			check:       checkMissingFrontmatter},
+		{Name: "invalid-yaml", Severity: SeverityError,
+			Description: "frontmatter is unterminated or is not valid YAML",
+			check:       checkInvalidYAML},
+		{Name: "missing-field", Severity: SeverityError,
+			Description: "frontmatter lacks a required field",
+			check:       checkMissingField},
+		{Name: "invalid-date", Severity: SeverityError,
+			Description: "frontmatter date is not an ISO date (YYYY-MM-DD)",
+			check:       checkInvalidDate},
+		{Name: "duplicate-id", Severity: SeverityError,
+			Description: "more than one ADR uses the same number",
+			check:       checkDuplicateID},
+		{Name: "id-mismatch", Severity: SeverityError,
+			Description: "filename number does not match the frontmatter id or ADR heading",
+			check:       checkIDMismatch},
+		{Name: "unknown-status", Severity: SeverityError,
+			Description: "status is not declared in the lifecycle",
+			check:       checkUnknownStatus},
+		{Name: "noncanonical-status", Severity: SeverityWarning,
+			Description: "status is an alias or differently capitalized; use the canonical name",
+			check:       checkNoncanonicalStatus},
+		{Name: "placeholder-text", Severity: SeverityWarning,
+			Description: "ADR still contains placeholder text from its template (error once the status is strict)",
+			check:       checkPlaceholderText},
+		{Name: "empty-section", Severity: SeverityWarning,
+			Description: "a section from the template has no content (error once the status is strict)",
+			check:       checkEmptySection},
+		{Name: "missing-section", Severity: SeverityError,
+			Description: "ADR lacks a section listed in lint.sections",
+			check:       checkMissingSection},
+	}
+}
+
+// Lint runs every enabled rule against the ADRs in dir.
+func Lint(dir string, cfg LintConfig) ([]Violation, error) {
+	rules := Rules()
+	known := map[string]bool{}
+	for _, r := range rules {
+		known[r.Name] = true
+	}
+	for name, sev := range cfg.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+		switch sev {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return nil, fmt.Errorf("lint rule %s: invalid severity %q (want error, warning or off)", name, sev)
+		}
+	}
+
+	var templates *templateModels
+	if enabled(cfg, "placeholder-text") || enabled(cfg, "empty-section") {
+		var err error
+		if templates, err = newTemplateModels(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := loadLintFiles(dir, cfg.IDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if templates != nil && f.Doc != nil {
+			f.template = templates.match(f)
+		}
+		f.strict = strictStatus(cfg, f.Meta.Status)
+	}
+
+	var out []Violation
+	for _, r := range rules {
+		sev := r.Severity
+		if s, ok := cfg.Rules[r.Name]; ok {
+			sev = s
+		}
+		if sev == SeverityOff {
+			continue
+		}
+		for _, v := range r.check(files, cfg) {
+			v.Rule = r.Name
+			v.Severity = sev
+			if v.strict && sev == SeverityWarning {
+				v.Severity = SeverityError
+			}
+			out = append(out, v)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].File != out[j].File {
+			return out[i].File < out[j].File
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out, nil
+}
+
+// enabled reports whether a rule is switched on by cfg.
+func enabled(cfg LintConfig, rule string) bool {
+	sev, ok := cfg.Rules[rule]
+	return !ok || sev != SeverityOff
+}
+
+func loadLintFiles(dir string, scheme IDScheme) ([]*lintFile, error) {
+	found, err := findADRs(dir, scheme)
+	if err != nil {
+		return nil, err
+	}
+	ids := scheme.matcher()
+	var files []*lintFile
+	for _, a := range found {
+		f, err := loadLintFile(filepath.Join(dir, filepath.FromSlash(a.File)))
+		if err != nil {
+			return nil, err
+		}
+		f.Rel, f.FileID, f.ids = a.File, a.ID, ids
+		written := f.Meta.ID
+		if _, v := f.field("id"); v != nil && v.Value != "" {
+			written = v.Value // the raw text, so an unquoted 0010 stays 0010
+		}
+		f.ID, _ = ids.entryID(written, a.ID)
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func loadLintFile(path string) (*lintFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &lintFile{Path: path}
+	f.Markup, _ = markupOf(path)
+
+	s := bufio.NewScanner(bytes.NewReader(content))
+	s.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for s.Scan() {
+		f.Lines = append(f.Lines, s.Text())
+	}
+
+	b, ok, err := locateMetadata(content, f.Markup)
+	if ok {
+		f.Frontmatter, err = decodeFrontmatter(content, b)
+		f.FrontmatterAt = bytes.Count(content[:b.start], []byte("\n")) + 1
+		f.format = b.format
+	}
+	var fe *FrontmatterError
+	if errors.As(err, &fe) {
+		f.YAMLErr, f.YAMLErrLine = fe.Err, fe.Line
+	}
+	f.HasFrontmatter = ok || err != nil
+
+	f.Doc, _ = parseDocument(content, f.Markup)
+	if f.Meta, err = ParseADR(path); err != nil && !errors.As(err, &fe) {
+		return nil, err
+	}
+	return f, nil
+}
+
+// field returns the key and value nodes for a top-level frontmatter key.
+func (f *lintFile) field(key string) (*yaml.Node, *yaml.Node) {
+	if f.Frontmatter == nil {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(f.Frontmatter.Content); i += 2 {
+		if f.Frontmatter.Content[i].Value == key {
+			return f.Frontmatter.Content[i], f.Frontmatter.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// fieldLine maps a frontmatter node to its line in the file.
+func (f *lintFile) fieldLine(n *yaml.Node) int {
+	return f.FrontmatterAt + n.Line - 1
+}
+
+// headerLine returns the line where the metadata starts: the opening
+// delimiter of YAML or TOML frontmatter, or the first line of the document
+// header, usually its title, for AsciiDoc attributes and rST fields.
+func (f *lintFile) headerLine() int {
+	if f.format == FrontmatterYAML || f.format == FrontmatterTOML {
+		return f.FrontmatterAt - 1
+	}
+	for i := 0; i < f.FrontmatterAt-1 && i < len(f.Lines); i++ {
+		line := strings.TrimSpace(f.Lines[i])
+		if line != "" && !(f.format == FrontmatterAttributes && isLineComment(line)) {
+			return i + 1
+		}
+	}
+	return f.FrontmatterAt
+}
+
+func checkMissingFrontmatter(files []*lintFile, _ LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		if !f.HasFrontmatter {
+			msg := "no YAML frontmatter"
+			switch f.Markup {
+			case MarkupAsciiDoc:
+				msg = "no attribute entries in the document header"
+			case MarkupRST:
+				msg = "no field list at the top of the document"
+			}
+			out = append(out, Violation{File: f.Path, Line: 1, Message: msg})
+		}
+	}
+	return out
+}
+
+func checkInvalidYAML(files []*lintFile, _ LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		if f.YAMLErr != nil {
+			out = append(out, Violation{File: f.Path, Line: f.YAMLErrLine, Message: f.YAMLErr.Error()})
+		}
+	}
+	return out
+}
+
+func checkMissingField(files []*lintFile, cfg LintConfig) []Violation {
+	required := cfg.Required
+	if len(required) == 0 {
+		required = []string{"id", "title", "status", "date"}
+	}
+	var out []Violation
+	for _, f := range files {
+		if f.Frontmatter == nil {
+			continue
+		}
+		for _, key := range required {
+			if _, v := f.field(key); v == nil || (v.Kind == yaml.ScalarNode && strings.TrimSpace(v.Value) == "") {
+				out = append(out, Violation{File: f.Path, Line: f.headerLine(), Message: fmt.Sprintf("frontmatter is missing %q", key)})
+			}
+		}
+	}
+	return out
+}
+
+func checkInvalidDate(files []*lintFile, _ LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		k, v := f.field("date")
+		if v == nil || v.Value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v.Value); err != nil {
+			out = append(out, Violation{File: f.Path, Line: f.fieldLine(k), Message: fmt.Sprintf("date %q is not a valid YYYY-MM-DD date", v.Value)})
+		}
+	}
+	return out
+}
+
+func checkDuplicateID(files []*lintFile, _ LintConfig) []Violation {
+	byID := map[string][]*lintFile{}
+	for _, f := range files {
+		byID[idKey(f.ID)] = append(byID[idKey(f.ID)], f)
+	}
+	var out []Violation
+	for _, group := range byID {
+		if len(group) < 2 {
+			continue
+		}
+		for _, f := range group {
+			var others []string
+			for _, o := range group {
+				if o != f {
+					others = append(others, o.Rel)
+				}
+			}
+			out = append(out, Violation{File: f.Path, Line: 1, Message: fmt.Sprintf("ADR number %s is also used by %s", f.ID, strings.Join(others, ", "))})
+		}
+	}
+	return out
+}
+
+func checkIDMismatch(files []*lintFile, _ LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		if k, v := f.field("id"); v != nil && v.Value != "" {
+			if !f.ids.sameID(v.Value, f.FileID) {
+				out = append(out, Violation{File: f.Path, Line: f.fieldLine(k), Message: fmt.Sprintf("frontmatter id %s does not match filename number %s", v.Value, f.FileID)})
+			}
+		}
+		for i, line := range markdownLines(f.Markup, f.Lines) {
+			if g := reADRTitle.FindStringSubmatch(line); len(g) == 3 {
+				if !f.ids.sameID(g[1], f.FileID) {
+					out = append(out, Violation{File: f.Path, Line: i + 1, Message: fmt.Sprintf("heading ADR %s does not match filename number %s", g[1], f.FileID)})
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
+// statusLine returns the line of the frontmatter status field, or 1.
+func (f *lintFile) statusLine() int {
+	if k, _ := f.field("status"); k != nil {
+		return f.fieldLine(k)
+	}
+	return 1
+}
+
+func checkUnknownStatus(files []*lintFile, cfg LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		if f.Meta.Status == "" {
+			continue
+		}
+		if _, ok := cfg.Lifecycle.Normalize(f.Meta.Status); !ok {
+			out = append(out, Violation{File: f.Path, Line: f.statusLine(), Message: cfg.Lifecycle.unknown(f.Meta.Status).Error()})
+		}
+	}
+	return out
+}
+
+func checkNoncanonicalStatus(files []*lintFile, cfg LintConfig) []Violation {
+	var out []Violation
+	for _, f := range files {
+		name, ok := cfg.Lifecycle.Normalize(f.Meta.Status)
+		if !ok {
+			continue
+		}
+		// "Superseded by ADR 0003" keeps its suffix; only the leading word is checked.
+		if word := f.Meta.Status[:min(len(name), len(f.Meta.Status))]; word != name {
+			out = append(out, Violation{File: f.Path, Line: f.statusLine(), Message: fmt.Sprintf("status %q should be written as %q", f.Meta.Status, name)})
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package adr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLintRules exercises every built-in rule against a directory of
+// deliberately broken ADRs.
+func TestLintRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	write("0001-good.md", "---\nid: 1\ntitle: \"Good\"\nstatus: \"Accepted\"\ndate: \"2025-01-15\"\n---\n\n# ADR 0001: Good\n")
+	write("0002-legacy.md", "# ADR 0002: Legacy\n\n## Status\nAccepted\n")
+	write("0003-bad-yaml.md", "---\nid: 3\ntitle: [unclosed\n---\n\n# ADR 0003: Bad YAML\n")
+	write("0004-missing.md", "---\nid: 4\ntitle: \"Missing\"\ndate: \"2025-02-30\"\n---\n")
+	write("0005-dup-a.md", "---\nid: 5\ntitle: \"A\"\nstatus: \"Proposed\"\ndate: \"2025-01-15\"\n---\n")
+	write("0005-dup-b.md", "---\nid: 5\ntitle: \"B\"\nstatus: \"Proposed\"\ndate: \"2025-01-15\"\n---\n")
+	write("0006-mismatch.md", "---\nid: 7\ntitle: \"Mismatch\"\nstatus: \"Proposed\"\ndate: \"2025-01-15\"\n---\n\n# ADR 0008: Mismatch\n")
+	write("0009-unterminated.md", "---\nid: 9\ntitle: \"Unterminated\"\n")
+	write("0010-alias.md", "---\nid: 10\ntitle: \"Alias\"\nstatus: approved\ndate: \"2025-01-15\"\n---\n")
+	write("0011-unknown.md", "---\nid: 11\ntitle: \"Unknown\"\nstatus: Working\ndate: \"2025-01-15\"\n---\n")
+	write("README.md", "not an ADR\n")
+
+	violations, err := Lint(dir, LintConfig{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, v := range violations {
+		got[filepath.Base(v.File)+":"+v.Rule] = true
+		if strings.HasPrefix(filepath.Base(v.File), "0001") {
+			t.Errorf("valid ADR should not be reported: %s", v)
+		}
+	}
+	want := []string{
+		"0002-legacy.md:missing-frontmatter",
+		"0003-bad-yaml.md:invalid-yaml",
+		"0004-missing.md:missing-field",
+		"0004-missing.md:invalid-date",
+		"0005-dup-a.md:duplicate-id",
+		"0005-dup-b.md:duplicate-id",
+		"0006-mismatch.md:id-mismatch",
+		"0009-unterminated.md:invalid-yaml",
+		"0010-alias.md:noncanonical-status",
+		"0011-unknown.md:unknown-status",
+	}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("missing violation %s; got %v", w, violations)
+		}
+	}
+
+	for _, v := range violations {
+		switch {
+		case v.Rule == "invalid-date" && v.Line != 4:
+			t.Errorf("invalid-date should point at line 4: %s", v)
+		case v.Rule == "id-mismatch" && strings.Contains(v.Message, "frontmatter") && v.Line != 2:
+			t.Errorf("id-mismatch should point at the id line: %s", v)
+		case v.Rule == "id-mismatch" && strings.Contains(v.Message, "heading") && v.Line != 8:
+			t.Errorf("id-mismatch should point at the heading line: %s", v)
+		case v.Rule == "missing-field" && v.Line != 1:
+			t.Errorf("missing-field should point at the opening delimiter: %s", v)
+		case v.Rule == "missing-frontmatter" && v.Severity != SeverityWarning:
+			t.Errorf("missing-frontmatter should default to warning: %s", v)
+		}
+	}
+}
+
+// TestLintLeadingZeroIDs verifies unquoted IDs such as 0010 are not read as
+// YAML octal numbers and reported as duplicates of 0008.
+func TestLintLeadingZeroIDs(t *testing.T) {
+	dir := t.TempDir()
+	for n := 8; n <= 12; n++ {
+		adr := fmt.Sprintf("---\nid: %04d\ntitle: \"ADR %d\"\nstatus: \"Accepted\"\ndate: \"2025-01-15\"\n---\n\n# ADR %04d: ADR %d\n", n, n, n, n)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%04d-adr-%d.md", n, n)), []byte(adr), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	violations, err := Lint(dir, LintConfig{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, v := range violations {
+		t.Errorf("unexpected violation: %s", v)
+	}
+}
+
+// TestLintConfig verifies rules can be disabled or downgraded from config.
+func TestLintConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0001-legacy.md"), []byte("# ADR 0002: Legacy\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := Lint(dir, LintConfig{Rules: map[string]Severity{
+		"missing-frontmatter": SeverityOff,
+		"id-mismatch":         SeverityWarning,
+	}})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Rule != "id-mismatch" || violations[0].Severity != SeverityWarning {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+	if got := violations[0].String(); !strings.HasSuffix(got, "0001-legacy.md:1: warning: heading ADR 0002 does not match filename number 0001 [id-mismatch]") {
+		t.Errorf("unexpected formatting: %s", got)
+	}
+
+	if _, err := Lint(dir, LintConfig{Rules: map[string]Severity{"no-such-rule": SeverityOff}}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+	if _, err := Lint(dir, LintConfig{Rules: map[string]Severity{"id-mismatch": "fatal"}}); err == nil {
+		t.Error("expected error for invalid severity")
+	}
+}
+
+// TestLintMissingFieldLine verifies a missing field is reported at the line
+// where the metadata starts in each markup.
+func TestLintMissingFieldLine(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          int
+	}{
+		{"0001-yaml.md", "\xEF\xBB\xBF---\nid: 1\n---\n\n# ADR 0001: YAML\n", 1},
+		{"0002-toml.md", "+++\nid = 2\n+++\n\n# ADR 0002: TOML\n", 1},
+		{"0003-title-first.adoc", "// note\n= ADR 0003: Title first\n:id: 3\n", 2},
+		{"0004-entries-first.adoc", ":id: 4\n= ADR 0004: Entries first\n", 1},
+		{"0005-fields.rst", "\nADR 0005: Fields\n================\n\n:id: 5\n", 2},
+	}
+	dir := t.TempDir()
+	want := map[string]int{}
+	for _, tt := range tests {
+		if err := os.WriteFile(filepath.Join(dir, tt.name), []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want[tt.name] = tt.want
+	}
+
+	violations, err := Lint(dir, LintConfig{Required: []string{"title"}})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, v := range violations {
+		if v.Rule != "missing-field" {
+			continue
+		}
+		name := filepath.Base(v.File)
+		seen[name] = true
+		if v.Line != want[name] {
+			t.Errorf("%s: got line %d, want %d", name, v.Line, want[name])
+		}
+	}
+	for name := range want {
+		if !seen[name] {
+			t.Errorf("%s: missing-field not reported; got %v", name, violations)
+		}
+	}
+}
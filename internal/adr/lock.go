@@ -0,0 +1,141 @@
+package adr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// lockFileName is the lock file that serializes ID allocation in an ADR
+// directory.
+const lockFileName = ".adrctl.lock"
+
+var (
+	lockTimeout = 10 * time.Second // how long to wait for another process
+	lockStale   = time.Minute      // age at which a lock is assumed abandoned
+)
+
+// maxIDAttempts bounds the retries when a new ADR collides with a file
+// created by a writer that does not take the lock.
+const maxIDAttempts = 10
+
+// lockDir takes the lock file in dir, waiting while another process holds
+// it. The returned function releases the lock.
+func lockDir(dir string) (func(), error) {
+	path := filepath.Join(dir, lockFileName)
+	deadline := time.Now().Add(lockTimeout)
+	wait := 5 * time.Millisecond
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			// the PID, and a token telling this lock from later ones
+			owner := fmt.Sprintf("%d %d-%d\n", os.Getpid(), time.Now().UnixNano(), lockSeq.Add(1))
+			_, err := f.WriteString(owner)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return func() {
+				// only remove the lock if it was not taken over meanwhile
+				if b, err := os.ReadFile(path); err == nil && string(b) == owner {
+					os.Remove(path)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		owner, rerr := os.ReadFile(path)
+		if fi, err := os.Stat(path); rerr == nil && err == nil && time.Since(fi.ModTime()) > lockStale {
+			takeOverLock(path, owner) // left behind by a process that crashed
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no other adrctl is running", path)
+		}
+		time.Sleep(wait)
+		if wait < 100*time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+// lockSeq tells apart the locks and stale lock names of one process.
+var lockSeq atomic.Int64
+
+// takeOverLock removes the stale lock at path, whose content was owner.
+// Another process may have replaced it since it was found stale, so the
+// file is first renamed aside and put back if it is not the stale one.
+func takeOverLock(path string, owner []byte) {
+	aside := fmt.Sprintf("%s.%d-%d", path, os.Getpid(), lockSeq.Add(1))
+	if err := os.Rename(path, aside); err != nil {
+		return // already taken over by another process
+	}
+	if b, err := os.ReadFile(aside); err != nil || !bytes.Equal(b, owner) {
+		os.Link(aside, path) // a live lock; fails if yet another took its place
+	}
+	os.Remove(aside)
+}
+
+// withNextID calls create with the next free ID while holding
+// the directory lock. create makes the ADR file and returns its path; a
+// directory-form ADR returns the path of its README.md. If
+// the file already exists, or another file claimed the same number in the
+// meantime, the new file is dropped and the next ID is tried.
+func (m Manager) withNextID(create func(idStr string) (string, error)) (string, string, error) {
+	unlock, err := lockDir(m.Dir)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
+
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		idStr, err := m.nextID()
+		if err != nil {
+			return "", "", err
+		}
+		path, err := create(idStr)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		rel, _ := filepath.Rel(m.Dir, path)
+		if taken, err := m.idTaken(idStr, filepath.ToSlash(rel)); err != nil || taken {
+			os.Remove(path)
+			if bundle := filepath.Dir(path); strings.HasPrefix(filepath.Base(bundle), idStr+"-") {
+				os.Remove(bundle) // the directory of a directory-form ADR
+			}
+			if err != nil {
+				return "", "", err
+			}
+			continue
+		}
+		return path, idStr, nil
+	}
+	return "", "", fmt.Errorf("could not allocate an ADR number in %s after %d attempts", m.Dir, maxIDAttempts)
+}
+
+// idTaken reports whether an ADR other than the one at except (relative to
+// the ADR directory) uses the ID id.
+func (m Manager) idTaken(id, except string) (bool, error) {
+	files, err := findADRs(m.Dir, m.Config.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range files {
+		if f.File != except && idKey(f.ID) == idKey(id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,105 @@
+package adr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWriteNewADRConcurrent verifies parallel WriteNewADR calls never hand
+// out the same number.
+func TestWriteNewADRConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+	const n = 40
+	paths := make([]string, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			paths[i], errs[i] = m.WriteNewADR(fmt.Sprintf("Decision %d", i), NewOptions{})
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[int]string{}
+	for i, path := range paths {
+		if errs[i] != nil {
+			t.Fatalf("WriteNewADR %d failed: %v", i, errs[i])
+		}
+		id, _ := IDScheme{}.matcher().fileID(filepath.Base(path))
+		num := idNumber(id)
+		if other, dup := seen[num]; dup {
+			t.Errorf("number %04d used by %s and %s", num, other, path)
+		}
+		seen[num] = path
+	}
+	for i := 1; i <= n; i++ {
+		if _, ok := seen[i]; !ok {
+			t.Errorf("number %04d was skipped", i)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, lockFileName)); !os.IsNotExist(err) {
+		t.Error("lock file left behind")
+	}
+}
+
+// TestLockDir verifies a held lock makes others wait and a stale lock is
+// taken over.
+func TestLockDir(t *testing.T) {
+	defer func(timeout, stale time.Duration) { lockTimeout, lockStale = timeout, stale }(lockTimeout, lockStale)
+	lockTimeout, lockStale = 50*time.Millisecond, time.Hour
+
+	dir := t.TempDir()
+	unlock, err := lockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockDir(dir); err == nil {
+		t.Error("second lock should time out while the first is held")
+	}
+	unlock()
+
+	path := filepath.Join(dir, lockFileName)
+	if err := os.WriteFile(path, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err = lockDir(dir)
+	if err != nil {
+		t.Fatalf("stale lock was not taken over: %v", err)
+	}
+
+	// Another process took the stale lock over between this one finding it
+	// stale and removing it: the new lock must survive.
+	takeOverLock(path, []byte("1\n"))
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("live lock removed by a late takeover: %v", err)
+	}
+	if _, err := lockDir(dir); err == nil {
+		t.Error("a late takeover should leave the live lock held")
+	}
+
+	// Once taken over, the old holder must not release the new lock.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("release removed a lock it no longer held: %v", err)
+	}
+	if files, _ := filepath.Glob(path + ".*"); len(files) != 0 {
+		t.Errorf("files left behind: %v", files)
+	}
+}
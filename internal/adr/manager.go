@@ -1,6 +1,7 @@
 package adr
 
 import (
+	"bytes"
 	"embed"
 	"errors"
 	"fmt"
@@ -11,19 +12,43 @@ import (
 	"time"
 )
 
-//go:embed templates/madr.md templates/nygard.md
+//go:embed templates/madr.md templates/nygard.md templates/madr.adoc templates/nygard.adoc templates/madr.rst templates/nygard.rst
 var builtinTemplates embed.FS
 
 // Manager holds settings for ADR operations.
 type Manager struct {
-	Dir string
+	Dir    string
+	Config Config
+}
+
+// NewManager returns a Manager for the ADR directory and defaults in cfg.
+func NewManager(cfg Config) Manager {
+	return Manager{Dir: cfg.Dir, Config: cfg}
 }
 
 // NewOptions controls ADR creation.
 type NewOptions struct {
-	Template string // "madr" | "nygard" | "/path/to/template.md"
-	Status   string // default: Proposed
-	Date     string // ISO date; default today
+	Template string // "madr" | "nygard" | "/path/to/template.md"; default from config
+	// Format is the file format of the ADR: "md", "adoc" or "rst"; default
+	// from config, else markdown. A template file sets the format by its
+	// extension.
+	Format string
+	Status string // default from config, else Proposed
+	Date   string // ISO date; default today
+
+	Supersedes IDList // ADRs the new record supersedes, written to frontmatter
+
+	// Draft writes the ADR to the drafts directory without a number; see
+	// Promote.
+	Draft bool
+
+	// Bundle creates the ADR as a directory, <id>-<slug>/README.md, so
+	// diagrams and other assets can sit next to it.
+	Bundle bool
+
+	// Category is the subdirectory of the ADR directory to create the ADR
+	// in, e.g. "platform".
+	Category string
 }
 
 func EnsureDir(dir string) error {
@@ -33,108 +58,197 @@ func EnsureDir(dir string) error {
 	return os.MkdirAll(dir, 0o755)
 }
 
-func (m Manager) nextID() (int, error) {
-	entries, err := os.ReadDir(m.Dir)
+// nextID returns the ID for a new ADR under the configured scheme. IDs are
+// unique across all categories.
+func (m Manager) nextID() (string, error) {
+	files, err := findADRs(m.Dir, m.Config.ID)
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return 0, err
+		return "", err
 	}
 	max := 0
-	for _, e := range entries {
-		name := e.Name()
-		if !e.IsDir() && len(name) >= 4 {
-			if n, ok := parseLeadingNumber(name); ok && n > max {
-				max = n
-			}
-		}
-	}
-	return max + 1, nil
-}
-
-func parseLeadingNumber(name string) (int, bool) {
-	var n int
-	for i := 0; i < len(name) && i < 6; i++ { // up to 6 digits
-		if name[i] < '0' || name[i] > '9' {
-			break
+	taken := map[string]bool{}
+	for _, f := range files {
+		taken[idKey(f.ID)] = true
+		if n := idNumber(f.ID); n > max {
+			max = n
 		}
-		n = n*10 + int(name[i]-'0')
 	}
-	if n == 0 {
-		return 0, false
+	if m.Config.ID.Sequential() {
+		return m.Config.ID.FormatNumber(max + 1), nil
 	}
-	return n, true
-}
-
-func sanitizeTitle(s string) string {
-	s = strings.TrimSpace(strings.ToLower(s))
-	s = strings.ReplaceAll(s, " ", "-")
-	s = strings.ReplaceAll(s, "/", "-")
-	s = strings.ReplaceAll(s, "_", "-")
-	s = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-			return r
-		}
-		return -1
-	}, s)
-	return s
+	return m.Config.ID.New(time.Now(), taken), nil
 }
 
 func (m Manager) WriteNewADR(title string, opt NewOptions) (string, error) {
-	if err := EnsureDir(m.Dir); err != nil {
-		return "", err
-	}
-	id, err := m.nextID()
-	if err != nil {
-		return "", err
-	}
-
-	file := fmt.Sprintf("%04d-%s.md", id, sanitizeTitle(title))
-	path := filepath.Join(m.Dir, file)
+	path, _, err := m.writeNewADR(title, opt)
+	return path, err
+}
 
+// writeNewADR creates the ADR and returns its path and zero-padded ID.
+func (m Manager) writeNewADR(title string, opt NewOptions) (string, string, error) {
 	if opt.Date == "" {
 		opt.Date = time.Now().Format("2006-01-02")
 	}
+	if opt.Status == "" {
+		opt.Status = m.Config.Status
+	}
 	if opt.Status == "" {
 		opt.Status = "Proposed"
 	}
-
-	tpl, err := m.loadTemplate(opt.Template)
+	status, ok := m.Config.Lifecycle.Normalize(opt.Status)
+	if !ok {
+		return "", "", m.Config.Lifecycle.unknown(opt.Status)
+	}
+	opt.Status = status
+	if opt.Template == "" {
+		opt.Template = m.Config.Template
+	}
+	if opt.Format == "" {
+		opt.Format = m.Config.Format
+	}
+	markup, err := ParseMarkup(opt.Format)
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+	if mk, ok := templateMarkup(opt.Template); ok {
+		markup = mk
 	}
+	ext := "." + markup
 
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	tpl, err := m.loadTemplate(opt.Template, markup)
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+
+	render := func(idStr string) ([]byte, error) {
+		data := map[string]any{
+			"ID":     idStr,
+			"Title":  title,
+			"Status": opt.Status,
+			"Date":   opt.Date,
+		}
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		content := buf.Bytes()
+		if len(opt.Supersedes) > 0 {
+			d, err := parseDocument(content, markup)
+			if err != nil {
+				return nil, fmt.Errorf("template %s: %w", opt.Template, err)
+			}
+			if err := d.Set("supersedes", opt.Supersedes); err != nil {
+				return nil, err
+			}
+			content = d.Bytes()
+		}
+		return content, nil
 	}
-	defer f.Close()
 
-	data := map[string]any{
-		"ID":     fmt.Sprintf("%04d", id),
-		"Title":  title,
-		"Status": opt.Status,
-		"Date":   opt.Date,
+	if opt.Draft && (opt.Bundle || opt.Category != "") {
+		return "", "", errors.New("a draft cannot be created as a bundle or in a category")
+	}
+	dir := m.Dir
+	if opt.Category != "" {
+		category := filepath.Clean(filepath.FromSlash(opt.Category))
+		if !filepath.IsLocal(category) || category == draftsDir || strings.HasPrefix(filepath.Base(category), ".") {
+			return "", "", fmt.Errorf("invalid category %q: want a subdirectory of %s", opt.Category, m.Dir)
+		}
+		dir = filepath.Join(m.Dir, category)
 	}
 
-	if err := tpl.Execute(f, data); err != nil {
-		return "", err
+	if err := m.checkDir(); err != nil {
+		return "", "", err
+	}
+	if opt.Draft {
+		if err := EnsureDir(m.DraftDir()); err != nil {
+			return "", "", err
+		}
+		content, err := render(DraftID)
+		if err != nil {
+			return "", "", err
+		}
+		path := filepath.Join(m.DraftDir(), Slugify(title, m.Config.Slug)+ext)
+		return path, DraftID, createFile(path, content)
+	}
+
+	if err := EnsureDir(dir); err != nil {
+		return "", "", err
 	}
-	return path, nil
+	return m.withNextID(func(idStr string) (string, error) {
+		content, err := render(idStr)
+		if err != nil {
+			return "", err
+		}
+		name := idStr + "-" + Slugify(title, m.Config.Slug)
+		if opt.Bundle {
+			bundle := filepath.Join(dir, name)
+			if err := os.Mkdir(bundle, 0o755); err != nil {
+				return "", err
+			}
+			path := filepath.Join(bundle, "README"+ext)
+			return path, createFile(path, content)
+		}
+		path := filepath.Join(dir, name+ext)
+		return path, createFile(path, content)
+	})
 }
 
-func (m Manager) loadTemplate(name string) (*template.Template, error) {
+// checkDir fails when the ADR directory does not exist. Only init creates
+// it, so a mistyped or undetected directory is not silently made.
+func (m Manager) checkDir() error {
+	fi, err := os.Stat(m.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("ADR directory %s does not exist; run adrctl init or pass --dir", m.Dir)
+	}
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("ADR directory %s is not a directory", m.Dir)
+	}
+	return nil
+}
+
+// createFile writes content to a new file, failing if path already exists.
+func createFile(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadTemplate loads a built-in template in the given format, or a
+// template file.
+func (m Manager) loadTemplate(name, markup string) (*template.Template, error) {
 	var content []byte
 	var err error
 
 	switch strings.ToLower(strings.TrimSpace(name)) {
 	case "", "madr":
-		content, err = builtinTemplates.ReadFile("templates/madr.md")
+		content, err = builtinTemplates.ReadFile("templates/madr." + markup)
 	case "nygard":
-		content, err = builtinTemplates.ReadFile("templates/nygard.md")
+		content, err = builtinTemplates.ReadFile("templates/nygard." + markup)
 	default:
 		content, err = os.ReadFile(name)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return template.New("adr").Parse(string(content))
+	return template.New("adr").Funcs(TemplateFuncs()).Parse(string(content))
+}
+
+// templateMarkup returns the format of a template file from its extension,
+// and false for built-in templates and files of other types.
+func templateMarkup(name string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "madr", "nygard":
+		return "", false
+	}
+	return markupOf(name)
 }
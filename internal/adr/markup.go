@@ -0,0 +1,456 @@
+package adr
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ADR file formats, named by their file extension.
+const (
+	MarkupMarkdown = "md"
+	MarkupAsciiDoc = "adoc"
+	MarkupRST      = "rst"
+)
+
+// Markups lists the formats ADRs can be written in.
+var Markups = []string{MarkupMarkdown, MarkupAsciiDoc, MarkupRST}
+
+// markupExts maps the extensions of ADR documents to their format.
+var markupExts = map[string]string{
+	".md":       MarkupMarkdown,
+	".adoc":     MarkupAsciiDoc,
+	".asciidoc": MarkupAsciiDoc,
+	".rst":      MarkupRST,
+}
+
+// ParseMarkup returns the ADR format named by s: an extension such as
+// "adoc" or ".rst", or a name such as "asciidoc" or "restructuredtext".
+// An empty s is markdown.
+func ParseMarkup(s string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), ".")) {
+	case "", "md", "markdown":
+		return MarkupMarkdown, nil
+	case "adoc", "asciidoc":
+		return MarkupAsciiDoc, nil
+	case "rst", "rest", "restructuredtext":
+		return MarkupRST, nil
+	}
+	return "", fmt.Errorf("unknown ADR format %q (want %s, %s or %s)", s, MarkupMarkdown, MarkupAsciiDoc, MarkupRST)
+}
+
+// markupOf returns the format of an ADR document from its file name. It
+// returns markdown and false when the extension is not an ADR format.
+func markupOf(name string) (string, bool) {
+	if m, ok := markupExts[filepath.Ext(name)]; ok {
+		return m, true
+	}
+	return MarkupMarkdown, false
+}
+
+var reAsciiDocHeading = regexp.MustCompile(`^(={1,6})\s+(\S.*?)\s*$`)
+
+// markdownLines returns the lines of an AsciiDoc or reStructuredText
+// document with their headings rewritten as markdown headings, so the
+// patterns used for markdown apply to every format: "== Status" and an
+// underlined "Status" both become "## Status". The underlines and overlines
+// of reStructuredText titles become blank, and other lines starting with #
+// are indented so they are not taken for headings. lines are without line
+// endings; the result has the same length. Markdown is returned as is.
+func markdownLines(markup string, lines []string) []string {
+	if markup != MarkupAsciiDoc && markup != MarkupRST {
+		return lines
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l
+		if strings.HasPrefix(l, "#") {
+			out[i] = " " + l
+		}
+	}
+
+	if markup == MarkupAsciiDoc {
+		delim := "" // open delimited block, such as a ---- listing
+		for i, l := range lines {
+			if d := asciidocDelimiter(l); d != "" {
+				switch delim {
+				case "":
+					delim = d
+				case d:
+					delim = ""
+				}
+				continue
+			}
+			if delim == "" {
+				if g := reAsciiDocHeading.FindStringSubmatch(l); g != nil {
+					out[i] = strings.Repeat("#", len(g[1])) + " " + g[2]
+				}
+			}
+		}
+		return out
+	}
+
+	// reStructuredText levels follow the order in which title styles
+	// first appear.
+	var styles []string
+	for i := 0; i < len(lines); i++ {
+		title, style, n := rstTitle(lines, i)
+		if n == 0 {
+			continue
+		}
+		level := 0
+		for level < len(styles) && styles[level] != style {
+			level++
+		}
+		if level == len(styles) {
+			styles = append(styles, style)
+		}
+		for j := i; j < i+n; j++ {
+			out[j] = ""
+		}
+		out[i+n-2] = strings.Repeat("#", min(level+1, 6)) + " " + title
+		i += n - 1
+	}
+	return out
+}
+
+// asciidocDelimiter returns line if it opens or closes an AsciiDoc
+// delimited block, whose content is not searched for headings.
+func asciidocDelimiter(line string) string {
+	line = strings.TrimRight(line, " \t")
+	if strings.HasPrefix(line, "```") {
+		return "```"
+	}
+	if len(line) < 4 || !strings.ContainsRune("-./+*_=", rune(line[0])) || strings.Trim(line, line[:1]) != "" {
+		return ""
+	}
+	return line
+}
+
+// rstTitle reports whether a reStructuredText section title starts at
+// lines[i]: a line of text underlined, and optionally overlined, with a
+// line of one punctuation character. It returns the title, its style (the
+// adornment character, doubled when overlined) and the number of lines it
+// spans, or 0 when there is no title.
+func rstTitle(lines []string, i int) (string, string, int) {
+	if i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+		return "", "", 0 // titles follow a blank line
+	}
+	line := strings.TrimRight(lines[i], " \t")
+	if isAdornment(line) {
+		if i+2 < len(lines) && isTitleText(lines[i+1]) && strings.TrimRight(lines[i+2], " \t") == line {
+			title := strings.TrimSpace(lines[i+1])
+			if textWidth(line) >= textWidth(title) {
+				return title, line[:1] + line[:1], 3
+			}
+		}
+		return "", "", 0
+	}
+	if i+1 < len(lines) && isTitleText(line) && !strings.HasPrefix(line, " ") {
+		under := strings.TrimRight(lines[i+1], " \t")
+		if isAdornment(under) && textWidth(under) >= min(textWidth(line), 4) {
+			return line, under[:1], 2
+		}
+	}
+	return "", "", 0
+}
+
+func isTitleText(line string) bool {
+	return strings.TrimSpace(line) != "" && !isAdornment(strings.TrimRight(line, " \t"))
+}
+
+// isAdornment reports whether line is a reStructuredText title underline
+// or overline: two or more of the same punctuation character.
+func isAdornment(line string) bool {
+	if len(line) < 2 || line[0] >= 0x80 || !(unicode.IsPunct(rune(line[0])) || unicode.IsSymbol(rune(line[0]))) {
+		return false
+	}
+	return strings.Trim(line, line[:1]) == ""
+}
+
+// textWidth returns the number of columns s takes in a monospaced font;
+// East Asian characters take two.
+func textWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || (r >= 0xFF01 && r <= 0xFF60) {
+			n++
+		}
+	}
+	return n
+}
+
+// underline returns a reStructuredText title adornment of c as wide as
+// text.
+func underline(c, text string) string {
+	return strings.Repeat(c, max(textWidth(text), 2))
+}
+
+// headingEnd returns the index of the last line of the heading at
+// lines[i], which may end in a line break: its underline in
+// reStructuredText, else i itself.
+func headingEnd(markup string, lines []string, i int) int {
+	if markup == MarkupRST && i+1 < len(lines) && isAdornment(strings.TrimRight(lines[i+1], " \t\r\n")) {
+		return i + 1
+	}
+	return i
+}
+
+var (
+	reAttributeEntry = regexp.MustCompile(`^:(!?)([A-Za-z0-9_][A-Za-z0-9_-]*)(!?):(?:[ \t]+(.*?))?[ \t]*$`)
+	reFieldEntry     = regexp.MustCompile(`^:([^:\s][^:]*?):(?:[ \t]+(.*?))?[ \t]*$`)
+)
+
+// fieldEntry parses an AsciiDoc attribute entry or a reStructuredText
+// field. The key is lower-cased; it is empty for an entry that unsets an
+// attribute.
+func fieldEntry(format, line string) (key, value string, ok bool) {
+	if format == FrontmatterAttributes {
+		g := reAttributeEntry.FindStringSubmatch(line)
+		if g == nil {
+			return "", "", false
+		}
+		if g[1] != "" || g[3] != "" {
+			return "", "", true
+		}
+		return strings.ToLower(g[2]), g[4], true
+	}
+	g := reFieldEntry.FindStringSubmatch(line)
+	if g == nil {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(g[1])), g[2], true
+}
+
+// lineTexts returns the start offset and the text, without its line
+// ending, of every line of content.
+func lineTexts(content []byte) ([]int, []string) {
+	var starts []int
+	var texts []string
+	for pos := 0; pos < len(content); {
+		line, next := readLine(content, pos)
+		starts = append(starts, pos)
+		texts = append(texts, strings.TrimRight(line, "\r"))
+		pos = next
+	}
+	return starts, texts
+}
+
+// locateMetadata finds the metadata block of a document: frontmatter,
+// which a document in any format may start with, else the attribute
+// entries of an AsciiDoc document header or the field list at the top of a
+// reStructuredText document.
+func locateMetadata(content []byte, markup string) (fmBlock, bool, error) {
+	if markup == MarkupRST {
+		// an overlined title is not frontmatter, even when its
+		// adornment is ---
+		if _, texts := lineTexts(bytes.TrimPrefix(content, utf8BOM)); len(texts) > 0 {
+			if _, _, n := rstTitle(texts, 0); n == 3 {
+				b, ok := locateFields(content, markup)
+				return b, ok, nil
+			}
+		}
+	}
+	b, ok, err := locateFrontmatter(content)
+	if ok || err != nil || (markup != MarkupAsciiDoc && markup != MarkupRST) {
+		return b, ok, err
+	}
+	b, ok = locateFields(content, markup)
+	return b, ok, nil
+}
+
+// locateFields finds the metadata of an AsciiDoc or reStructuredText ADR:
+// the attribute entries (":status: Accepted") of an AsciiDoc document
+// header, or a field list (":Status: Accepted") at the top of a
+// reStructuredText document, before or right after its title. The entries
+// are part of the body, which starts at the top of the file.
+func locateFields(content []byte, markup string) (fmBlock, bool) {
+	b := fmBlock{format: FrontmatterFields, newline: "\n"}
+	if markup == MarkupAsciiDoc {
+		b.format = FrontmatterAttributes
+	}
+	if bytes.Contains(content, []byte("\r\n")) {
+		b.newline = "\r\n"
+	}
+	starts, texts := lineTexts(content)
+	if len(texts) > 0 {
+		texts[0] = strings.TrimPrefix(texts[0], string(utf8BOM))
+	}
+	blank := func(i int) bool { return strings.TrimSpace(texts[i]) == "" }
+	entry := func(i int) bool {
+		_, _, ok := fieldEntry(b.format, strings.TrimRight(texts[i], " \t"))
+		return ok
+	}
+
+	first, last := -1, -1
+	i := 0
+	if b.format == FrontmatterAttributes {
+		for i < len(texts) && (blank(i) || isLineComment(texts[i])) {
+			i++
+		}
+		// the header ends at the first blank line; attribute entries may
+		// come before or after the document title
+		for ; i < len(texts) && !blank(i); i++ {
+			continues := first >= 0 && last == i-1 && strings.HasSuffix(strings.TrimRight(texts[i-1], " \t"), " \\")
+			switch {
+			case entry(i) || continues || (first >= 0 && isLineComment(texts[i])):
+				if first < 0 {
+					first = i
+				}
+				last = i
+			case first >= 0:
+				i = len(texts) // the entries end here
+			}
+		}
+	} else {
+		for i < len(texts) && blank(i) {
+			i++
+		}
+		if i < len(texts) && !entry(i) {
+			_, _, n := rstTitle(texts, i)
+			i += n
+			for n > 0 && i < len(texts) && blank(i) {
+				i++
+			}
+		}
+		for ; i < len(texts) && !blank(i); i++ {
+			indented := first >= 0 && (texts[i][0] == ' ' || texts[i][0] == '\t')
+			if !entry(i) && !indented {
+				break
+			}
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return fmBlock{}, false
+	}
+	b.start, b.end = starts[first], len(content)
+	if last+1 < len(starts) {
+		b.end = starts[last+1]
+	}
+	return b, true
+}
+
+func isLineComment(line string) bool {
+	return strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "////")
+}
+
+// parseFields parses AsciiDoc attribute entries or a reStructuredText field
+// list into a mapping of strings, like decoded frontmatter. A later entry
+// for a key that is already set is ignored. Values continued on the next
+// line (ending in " \" in AsciiDoc, indented in reStructuredText) are
+// joined with a space.
+func parseFields(text, format string) *yaml.Node {
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	seen := map[string]bool{}
+	var value *yaml.Node
+	more := false
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if key, v, ok := fieldEntry(format, line); ok {
+			value = nil
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Line: i + 1, Column: len(key) + 4}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, Line: i + 1, Column: 2}, value)
+			value.Value, more = strings.CutSuffix(v, " \\")
+			continue
+		}
+		cont := strings.TrimSpace(line)
+		if value == nil || cont == "" || (format == FrontmatterAttributes && !more) {
+			continue
+		}
+		if format == FrontmatterAttributes {
+			cont, more = strings.CutSuffix(cont, " \\")
+		}
+		value.Value = strings.TrimSpace(value.Value + " " + cont)
+	}
+	return m
+}
+
+// renderField formats a single ":key: value" attribute entry or field,
+// ending in a newline. Lists are written comma-separated; nested values
+// cannot be written.
+func renderField(format, key string, n *yaml.Node) (string, error) {
+	var value string
+	switch n.Kind {
+	case yaml.ScalarNode:
+		value = n.Value
+	case yaml.SequenceNode:
+		var items []string
+		for _, c := range n.Content {
+			if c.Kind != yaml.ScalarNode {
+				return "", fieldsError(format)
+			}
+			items = append(items, c.Value)
+		}
+		value = strings.Join(items, ", ")
+	default:
+		return "", fieldsError(format)
+	}
+	cont := "\n   "
+	if format == FrontmatterAttributes {
+		cont = " \\\n"
+	}
+	entry := ":" + key + ":"
+	if value != "" {
+		entry += " " + strings.ReplaceAll(value, "\n", cont)
+	}
+	return entry + "\n", nil
+}
+
+func fieldsError(format string) error {
+	if format == FrontmatterAttributes {
+		return fmt.Errorf("AsciiDoc attributes cannot hold nested values")
+	}
+	return fmt.Errorf("reStructuredText fields cannot hold nested values")
+}
+
+// fieldName returns the key of the attribute entry or field starting the
+// line, as written.
+func fieldName(line []byte) string {
+	name, _, _ := strings.Cut(string(bytes.TrimPrefix(line, []byte(":"))), ":")
+	return name
+}
+
+// fieldsInsertAt returns where the first attribute entry or field goes in
+// a document that has none, and whether it needs a blank line after it:
+// at the end of an AsciiDoc header, else at the top of the file.
+func fieldsInsertAt(content []byte, markup string) (int, bool) {
+	top := 0
+	if bytes.HasPrefix(content, utf8BOM) {
+		top = len(utf8BOM)
+	}
+	if markup != MarkupAsciiDoc {
+		return top, true
+	}
+	starts, texts := lineTexts(content)
+	i := 0
+	for i < len(texts) && (strings.TrimSpace(texts[i]) == "" || isLineComment(texts[i])) {
+		i++
+	}
+	if i == len(texts) || !strings.HasPrefix(texts[i], "= ") {
+		if i < len(starts) {
+			top = max(top, starts[i])
+		}
+		return top, true
+	}
+	for i < len(texts) && strings.TrimSpace(texts[i]) != "" {
+		i++
+	}
+	if i == len(texts) {
+		return len(content), false
+	}
+	return starts[i], false
+}
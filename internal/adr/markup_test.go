@@ -0,0 +1,224 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseMarkup verifies that AsciiDoc and reStructuredText ADRs are read
+// from attribute entries or field lists, and from their headings when the
+// metadata is missing.
+func TestParseMarkup(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    Meta
+		extra   string // the deciders field
+	}{
+		{
+			name:    "asciidoc attributes",
+			file:    "0007-use-go.adoc",
+			content: "// comment\n= ADR 0007: Use Go\n:id: 0007\n:title: Use Go\n:status: Accepted\n:date: 2025-01-15\n:depends_on: 3, 0005\n\n== Context\n",
+			want:    Meta{ID: "0007", Title: "Use Go", Status: "Accepted", Date: "2025-01-15", Relations: Relations{DependsOn: IDList{"3", "0005"}}},
+		},
+		{
+			name:    "asciidoc continued value",
+			file:    "0007-use-go.adoc",
+			content: "= ADR 7: Use Go\n:!toc:\n:status: Superseded by \\\n  ADR 0009\n:date: 2025-01-15\n",
+			want:    Meta{ID: "7", Title: "Use Go", Status: "Superseded by ADR 0009", Date: "2025-01-15"},
+		},
+		{
+			name:    "asciidoc headings",
+			file:    "0007-use-go.adoc",
+			content: "= ADR 7: Use Go\n\n----\n== Status\nlisting\n----\n\n== Status\n\nProposed\n\nDate: 2025-01-15\n",
+			want:    Meta{ID: "7", Title: "Use Go", Status: "Proposed", Date: "2025-01-15"},
+		},
+		{
+			name:    "rst fields before the title",
+			file:    "0007-use-go.rst",
+			content: ":ID: 0007\n:Title: Use Go\n:Status: Accepted\n:Date: 2025-01-15\n:Supersedes: 3\n\nADR 0007: Use Go\n================\n",
+			want:    Meta{ID: "0007", Title: "Use Go", Status: "Accepted", Date: "2025-01-15", Relations: Relations{Supersedes: IDList{"3"}}},
+		},
+		{
+			name:    "rst fields after the title",
+			file:    "0007-use-go.rst",
+			content: "================\nADR 0007: Use Go\n================\n\n:status: Accepted\n:date: 2025-01-15\n:deciders: Alice,\n   Bob\n",
+			want:    Meta{ID: "0007", Title: "Use Go", Status: "Accepted", Date: "2025-01-15"},
+			extra:   "Alice, Bob",
+		},
+		{
+			name:    "rst headings",
+			file:    "0007-use-go.rst",
+			content: "ADR 7: Use Go\n=============\n\nStatus\n------\n\nAccepted\n\nDate: 2025-01-15\n",
+			want:    Meta{ID: "7", Title: "Use Go", Status: "Accepted", Date: "2025-01-15"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := ParseADR(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Number = idNumber(tt.want.ID)
+			if m.ID != tt.want.ID || m.Number != tt.want.Number || m.Title != tt.want.Title || m.Status != tt.want.Status || m.Date != tt.want.Date {
+				t.Errorf("got %+v, want %+v", m, tt.want)
+			}
+			if strings.Join(m.DependsOn, ",") != strings.Join(tt.want.DependsOn, ",") || strings.Join(m.Supersedes, ",") != strings.Join(tt.want.Supersedes, ",") {
+				t.Errorf("relations: got %+v, want %+v", m.Relations, tt.want.Relations)
+			}
+			if got := FormatValue(m.Extra.Get("deciders"), ", "); got != tt.extra {
+				t.Errorf("deciders: got %q, want %q", got, tt.extra)
+			}
+		})
+	}
+}
+
+// TestEditMarkup verifies that edits to AsciiDoc and reStructuredText ADRs
+// change the attribute entries or fields in place, keep the spelling of
+// their names, and add entries where the format expects them.
+func TestEditMarkup(t *testing.T) {
+	tests := []struct {
+		name    string
+		markup  string
+		content string
+		want    string
+	}{
+		{
+			name:    "asciidoc",
+			markup:  MarkupAsciiDoc,
+			content: "= ADR 0007: Use Go\n:id: 0007\n:status: Proposed\n// reviewed\n:date: 2025-01-15\n\n* Status: Proposed\n",
+			want:    "= ADR 0007: Use Go\n:id: 0007\n:status: Accepted\n// reviewed\n:date: 2025-01-15\n:supersedes: 0003, 0004\n\n* Status: Accepted\n",
+		},
+		{
+			name:    "asciidoc without attributes",
+			markup:  MarkupAsciiDoc,
+			content: "= ADR 0007: Use Go\nAlice <alice@example.com>\n\n== Status\n\nProposed\n",
+			want:    "= ADR 0007: Use Go\nAlice <alice@example.com>\n:status: Accepted\n:supersedes: 0003, 0004\n\n== Status\n\nAccepted\n",
+		},
+		{
+			name:    "rst",
+			markup:  MarkupRST,
+			content: ":ID: 0007\n:Status: Proposed\n\nADR 0007: Use Go\n================\n\nStatus\n------\n",
+			want:    ":ID: 0007\n:Status: Accepted\n:supersedes: 0003, 0004\n\nADR 0007: Use Go\n================\n\nStatus\n------\nAccepted\n",
+		},
+		{
+			name:    "rst without fields",
+			markup:  MarkupRST,
+			content: "ADR 0007: Use Go\n================\n\n- Status: Proposed\n",
+			want:    ":status: Accepted\n:supersedes: 0003, 0004\n\nADR 0007: Use Go\n================\n\n- Status: Accepted\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseDocument([]byte(tt.content), tt.markup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !d.HasFrontmatter() {
+				if err := d.Set("status", "Accepted"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := rewriteStatus(d, "Accepted"); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Set("supersedes", IDList{"0003", "0004"}); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(d.Bytes()); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if err := appendHistory(d, "Proposed", "Accepted", "2025-01-16"); err == nil {
+				t.Error("status_history should not be writable as a field")
+			}
+		})
+	}
+}
+
+// TestNewMarkup verifies that every built-in template exists in every
+// format, produces an ADR that parses back, and that drafts keep their
+// format when promoted.
+func TestNewMarkup(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+	for _, markup := range Markups {
+		for _, tpl := range []string{"madr", "nygard"} {
+			title := "Use " + tpl + " in " + markup
+			path, err := m.WriteNewADR(title, NewOptions{Template: tpl, Format: markup, Date: "2025-01-15"})
+			if err != nil {
+				t.Fatalf("%s/%s: %v", tpl, markup, err)
+			}
+			if filepath.Ext(path) != "."+markup {
+				t.Errorf("%s/%s: created %s", tpl, markup, path)
+			}
+			meta, err := ParseADR(path)
+			if err != nil || meta.Title != title || meta.Status != "Proposed" || meta.Date != "2025-01-15" {
+				t.Errorf("%s/%s: ParseADR got %+v, %v", tpl, markup, meta, err)
+			}
+			d, err := LoadDocument(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, s := range d.Sections() {
+				names = append(names, s.Name)
+			}
+			want := "title context decision-drivers considered-options decision consequences-positive consequences-negative pros-and-cons links"
+			if tpl == "nygard" {
+				want = "title status date context decision consequences"
+			}
+			if got := strings.Join(names, " "); got != want {
+				t.Errorf("%s/%s: sections %s, want %s", tpl, markup, got, want)
+			}
+		}
+	}
+	if entries, err := Scan(dir); err != nil || len(entries) != 6 {
+		t.Fatalf("Scan found %d ADRs, err %v", len(entries), err)
+	}
+	if _, err := m.WriteNewADR("Use TOML", NewOptions{Format: "toml"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	if _, err := m.WriteNewADR("Use Sphinx", NewOptions{Draft: true, Format: MarkupRST}); err != nil {
+		t.Fatal(err)
+	}
+	path, err := m.Promote("use-sphinx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "0007-use-sphinx.rst"); path != want {
+		t.Errorf("promoted path = %s, want %s", path, want)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), ":id: 0007\n") || !strings.Contains(string(content), "\nADR 0007: Use Sphinx\n=====================\n") {
+		t.Errorf("promoted ADR:\n%s", content)
+	}
+}
+
+// TestMarkdownLines covers the heading levels of reStructuredText, which
+// follow the order title styles first appear in, and AsciiDoc delimited
+// blocks.
+func TestMarkdownLines(t *testing.T) {
+	tests := []struct {
+		markup string
+		text   string
+		want   string
+	}{
+		{MarkupRST, "=====\nTitle\n=====\n\nOne\n---\n\nTwo\n~~~\n\nThree\n-----\ntext\n# not a heading", "|# Title|||## One|||### Two|||## Three||text| # not a heading"},
+		{MarkupAsciiDoc, "= Title\n\n....\n== literal\n....\n=== Sub\n# shell", "# Title||....|== literal|....|### Sub| # shell"},
+		{MarkupMarkdown, "# Title\n== kept", "# Title|== kept"},
+	}
+	for _, tt := range tests {
+		got := strings.Join(markdownLines(tt.markup, strings.Split(tt.text, "\n")), "|")
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.markup, got, tt.want)
+		}
+	}
+}
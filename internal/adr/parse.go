@@ -1,8 +1,6 @@
 package adr
 
 import (
-	"bufio"
-	"bytes"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -12,60 +10,175 @@ import (
 )
 
 var (
-	reADRTitle = regexp.MustCompile(`(?i)^#\s*ADR\s+(\d+)\s*:\s*(.+)$`)
+	reADRTitle = regexp.MustCompile(`(?i)^#\s*ADR\s+([0-9A-Za-z][0-9A-Za-z._-]*)\s*:\s*(.+)$`)
 	reStatus   = regexp.MustCompile(`(?i)^##\s*Status\s*$`)
 	reStatusKV = regexp.MustCompile(`(?i)^(\*\*Status:\*\*|[-*]\s*Status:?|\s*Status:)\s*(.+)$`)
-	reDateKV   = regexp.MustCompile(`(?i)^(Date|Date\s*:\s*)\s*:?[\s]*([0-9]{4}-[0-9]{2}-[0-9]{2}).*$`)
+	reDateKV   = regexp.MustCompile(`(?i)^(\*\*Date:\*\*|[-*]\s*Date:?|\s*Date\s*:?)\s*([0-9]{4}-[0-9]{2}-[0-9]{2}).*$`)
+
+	reSupersededBy = regexp.MustCompile(`(?i)^Superseded\s+by\s+(?:ADR\s+)?(\S+)`)
 )
 
 type Meta struct {
-	Number int
+	ID     string // as written in the frontmatter or heading, e.g. 0007 or SEC-0007
+	Number int    // the number in ID, or 0 when it is not a sequential ID
 	Title  string
 	Status string
 	Date   string // YYYY-MM-DD
+	Relations
+	Extra Fields // frontmatter keys adrctl does not interpret, in file order
 }
 
 type Frontmatter struct {
-	ID     any    `yaml:"id"`
-	Title  string `yaml:"title"`
-	Status string `yaml:"status"`
-	Date   string `yaml:"date"`
+	ID        string `yaml:"id"` // raw scalar text, so an unquoted 0010 stays 0010
+	Title     string `yaml:"title"`
+	Status    string `yaml:"status"`
+	Date      string `yaml:"date"`
+	Relations `yaml:",inline"`
+	Extra     Fields `yaml:"-"`
 }
 
-// parseFrontmatter extracts YAML frontmatter from file content
-func parseFrontmatter(content []byte) (*Frontmatter, []byte, error) {
-	if !bytes.HasPrefix(content, []byte("---\n")) {
-		return nil, content, nil
+// knownFrontmatterKeys are the keys decoded into Frontmatter fields; all
+// others are kept in Frontmatter.Extra.
+var knownFrontmatterKeys = map[string]bool{"id": true, "title": true, "status": true, "date": true}
+
+func init() {
+	for _, k := range RelationKinds {
+		knownFrontmatterKeys[k] = true
 	}
+}
+
+// Relations are the typed links between ADRs declared in frontmatter.
+type Relations struct {
+	Supersedes   IDList `yaml:"supersedes,omitempty" json:"supersedes,omitempty"`
+	SupersededBy IDList `yaml:"superseded_by,omitempty" json:"superseded_by,omitempty"`
+	Amends       IDList `yaml:"amends,omitempty" json:"amends,omitempty"`
+	AmendedBy    IDList `yaml:"amended_by,omitempty" json:"amended_by,omitempty"`
+	DependsOn    IDList `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	RelatesTo    IDList `yaml:"relates_to,omitempty" json:"relates_to,omitempty"`
+}
+
+// RelationKinds lists the relation frontmatter keys in display order.
+var RelationKinds = []string{"supersedes", "superseded_by", "amends", "amended_by", "depends_on", "relates_to"}
 
-	// Find the end of frontmatter
-	end := bytes.Index(content[4:], []byte("\n---\n"))
-	if end == -1 {
-		return nil, content, nil
+// Get returns the references for a relation key such as "depends_on".
+func (r Relations) Get(kind string) IDList {
+	switch kind {
+	case "supersedes":
+		return r.Supersedes
+	case "superseded_by":
+		return r.SupersededBy
+	case "amends":
+		return r.Amends
+	case "amended_by":
+		return r.AmendedBy
+	case "depends_on":
+		return r.DependsOn
+	case "relates_to":
+		return r.RelatesTo
 	}
+	return nil
+}
 
-	yamlContent := content[4 : end+4]
-	remaining := content[end+9:] // Skip past the closing ---
+// IDList is a list of ADR references as written in frontmatter. It accepts
+// a single value (supersedes: 3), a comma-separated list (supersedes: 3, 4,
+// as AsciiDoc attributes and reStructuredText fields write it) or a
+// sequence (supersedes: [3, 4]).
+type IDList []string
 
-	var fm Frontmatter
-	if err := yaml.Unmarshal(yamlContent, &fm); err != nil {
+func (l *IDList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = nil
+		for _, id := range strings.Split(node.Value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				*l = append(*l, id)
+			}
+		}
+		return nil
+	}
+	var ids []string
+	if err := node.Decode(&ids); err != nil {
+		return err
+	}
+	*l = ids
+	return nil
+}
+
+// Contains reports whether the list references the given ADR id.
+func (l IDList) Contains(id string) bool {
+	key := idKey(id)
+	for _, v := range l {
+		if idKey(v) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// idKey normalizes an ADR reference so "7", "0007" and "ADR-0007" compare
+// equal, as do "SEC-7" and "sec-0007". Other IDs compare case-insensitively.
+func idKey(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, ':'); i > 0 {
+		// an ID namespaced by its source, e.g. billing:0003
+		return s[:i] + ":" + idKey(s[i+1:])
+	}
+	if len(s) > 3 && strings.EqualFold(s[:3], "ADR") {
+		s = strings.TrimLeft(s[3:], "- ")
+	}
+	if g := reNumberedID.FindStringSubmatch(s); g != nil {
+		n := strings.TrimLeft(g[2], "0")
+		if n == "" {
+			n = "0"
+		}
+		if prefix := strings.TrimRight(g[1], "-_"); prefix != "" {
+			return strings.ToUpper(prefix) + "-" + n
+		}
+		return n
+	}
+	return strings.ToUpper(s)
+}
+
+// parseFrontmatter extracts YAML or TOML frontmatter, or the attribute
+// entries or field list of an AsciiDoc or reStructuredText ADR, from file
+// content. It returns a *FrontmatterError for frontmatter that cannot be
+// read.
+func parseFrontmatter(content []byte, markup string) (*Frontmatter, []byte, error) {
+	b, ok, err := locateMetadata(content, markup)
+	if !ok {
 		return nil, content, err
 	}
+	node, err := decodeFrontmatter(content, b)
+	if err != nil {
+		return nil, content[b.body:], err
+	}
+	var fm Frontmatter
+	if err := node.Decode(&fm); err != nil {
+		return nil, content[b.body:], &FrontmatterError{Line: 2, Err: err}
+	}
+	fm.Extra = fieldsFromNode(node, knownFrontmatterKeys)
 
-	return &fm, remaining, nil
+	return &fm, content[b.body:], nil
 }
 
-// ParseADR parses minimal metadata from an ADR file.
+// ParseADR parses minimal metadata from an ADR file, in the format given
+// by its extension. When the file has frontmatter that cannot be read, the
+// metadata found in the body is returned along with a *FrontmatterError.
 func ParseADR(path string) (Meta, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return Meta{}, err
 	}
+	markup, _ := markupOf(path)
 
 	var m Meta
 
-	// Try to parse frontmatter first
-	if fm, remaining, err := parseFrontmatter(content); err == nil && fm != nil {
+	// Try to parse frontmatter first. Broken frontmatter is reported with
+	// whatever the legacy parsing below finds in the body.
+	fm, remaining, fmErr := parseFrontmatter(content, markup)
+	if fmErr != nil {
+		content = remaining
+	}
+	if fm != nil {
 		// Convert frontmatter to Meta
 		if fm.Title != "" {
 			m.Title = fm.Title
@@ -76,20 +189,13 @@ func ParseADR(path string) (Meta, error) {
 		if fm.Date != "" {
 			m.Date = fm.Date
 		}
-		// Handle ID field which can be int or string
-		if fm.ID != nil {
-			switch id := fm.ID.(type) {
-			case int:
-				m.Number = id
-			case string:
-				m.Number = atoi(id)
-			case float64: // YAML can decode numbers as float64
-				m.Number = int(id)
-			}
-		}
+		m.Relations = fm.Relations
+		m.Extra = fm.Extra
+		m.ID = strings.TrimSpace(fm.ID)
+		m.Number = idNumber(m.ID)
 
 		// If we have all required fields from frontmatter, use them
-		if m.Title != "" && m.Status != "" && m.Date != "" && m.Number != 0 {
+		if m.Title != "" && m.Status != "" && m.Date != "" && m.ID != "" {
 			return m, nil
 		}
 
@@ -97,19 +203,17 @@ func ParseADR(path string) (Meta, error) {
 		content = remaining
 	}
 
-	// Fallback to original parsing logic for backward compatibility
-	s := bufio.NewScanner(bytes.NewReader(content))
-	s.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
-
+	// Fallback to original parsing logic for backward compatibility.
+	// AsciiDoc and reStructuredText headings are read as markdown ones.
+	_, lines := lineTexts(content)
 	var sawStatusHeader bool
 
-	for s.Scan() {
-		line := s.Text()
-
-		if m.Number == 0 || m.Title == "" {
+	for _, line := range markdownLines(markup, lines) {
+		if m.ID == "" || m.Title == "" {
 			if g := reADRTitle.FindStringSubmatch(line); len(g) == 3 {
-				if m.Number == 0 {
-					m.Number = atoi(g[1])
+				if m.ID == "" {
+					m.ID = g[1]
+					m.Number = idNumber(m.ID)
 				}
 				if m.Title == "" {
 					m.Title = strings.TrimSpace(g[2])
@@ -141,6 +245,13 @@ func ParseADR(path string) (Meta, error) {
 		}
 	}
 
+	if len(m.SupersededBy) == 0 {
+		// Legacy ADRs record the link only in their status line
+		if g := reSupersededBy.FindStringSubmatch(m.Status); g != nil {
+			m.SupersededBy = IDList{g[1]}
+		}
+	}
+
 	if m.Date == "" {
 		// fall back to file mod time
 		if fi, err := os.Stat(path); err == nil {
@@ -151,6 +262,10 @@ func ParseADR(path string) (Meta, error) {
 	if m.Title == "" {
 		// fallback: derive title from filename
 		base := filepath.Base(path)
+		if bundleFile(filepath.Dir(path)) == base {
+			// directory-form ADR: the directory carries the name
+			base = filepath.Base(filepath.Dir(path))
+		}
 		base = strings.TrimSuffix(base, filepath.Ext(base))
 		parts := strings.SplitN(base, "-", 2)
 		if len(parts) == 2 {
@@ -158,17 +273,5 @@ func ParseADR(path string) (Meta, error) {
 		}
 	}
 
-	return m, nil
-}
-
-func atoi(s string) int {
-	var n int
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c < '0' || c > '9' {
-			break
-		}
-		n = n*10 + int(c-'0')
-	}
-	return n
+	return m, fmErr
 }
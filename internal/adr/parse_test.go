@@ -1,6 +1,7 @@
 package adr
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -156,6 +157,7 @@ func TestParseEdgeCases(t *testing.T) {
 		content  string
 		filename string
 		expected Meta
+		fmErr    bool // a *FrontmatterError is reported alongside the result
 	}{
 		{
 			name:     "no frontmatter, derive from filename",
@@ -171,6 +173,7 @@ func TestParseEdgeCases(t *testing.T) {
 		{
 			name:     "malformed frontmatter falls back to content",
 			filename: "0099-fallback-test.md",
+			fmErr:    true,
 			content: `---
 invalid yaml: [
 ---
@@ -198,7 +201,11 @@ Status: Working`,
 			}
 
 			result, err := ParseADR(tmpFile)
-			if err != nil {
+			var fe *FrontmatterError
+			if tt.fmErr && !errors.As(err, &fe) {
+				t.Fatalf("expected a frontmatter diagnostic, got %v", err)
+			}
+			if !tt.fmErr && err != nil {
 				t.Fatalf("ParseADR failed: %v", err)
 			}
 
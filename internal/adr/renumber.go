@@ -0,0 +1,698 @@
+package adr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenumberOptions controls PlanRenumber.
+type RenumberOptions struct {
+	// Keep lists ADR files (by name or path) that keep their number when it
+	// is shared with other files. Without it, the file first committed to
+	// git keeps the number.
+	Keep []string
+
+	// CloseGaps renumbers sequential ADRs so no number between the first
+	// and the last is unused. Without it, gaps are only reported.
+	CloseGaps bool
+}
+
+// Renumbering changes the ID of one ADR file. When NewFile equals File the
+// file keeps its name and only its frontmatter id and heading are fixed.
+type Renumbering struct {
+	File    string
+	NewFile string
+	OldID   string
+	NewID   string
+	Reason  string
+}
+
+// LinkEdit is a reference to a renumbered ADR that will be rewritten: a
+// link to its file, or its ID in a frontmatter relation such as supersedes.
+type LinkEdit struct {
+	File string // file containing the reference, by its current name
+	Line int
+	Key  string // frontmatter relation; empty for a link to the file
+	Old  string
+	New  string
+}
+
+// RenumberPlan lists everything ApplyRenumber will change.
+type RenumberPlan struct {
+	Renumberings []Renumbering
+	Links        []LinkEdit
+	Gaps         []string // unused sequential IDs left by the plan, e.g. 0004 or 0006-0009
+}
+
+// Empty reports whether there is nothing to renumber.
+func (p RenumberPlan) Empty() bool { return len(p.Renumberings) == 0 }
+
+func (p RenumberPlan) String() string {
+	var b strings.Builder
+	for _, r := range p.Renumberings {
+		if r.NewFile == r.File {
+			fmt.Fprintf(&b, "%s: id %s -> %s (%s)\n", r.File, r.OldID, r.NewID, r.Reason)
+		} else {
+			fmt.Fprintf(&b, "%s -> %s (%s)\n", r.File, r.NewFile, r.Reason)
+		}
+	}
+	for _, l := range p.Links {
+		if l.Key != "" {
+			fmt.Fprintf(&b, "%s:%d: %s %s -> %s\n", l.File, l.Line, l.Key, l.Old, l.New)
+		} else {
+			fmt.Fprintf(&b, "%s:%d: link %s -> %s\n", l.File, l.Line, l.Old, l.New)
+		}
+	}
+	for _, g := range p.Gaps {
+		fmt.Fprintf(&b, "gap: %s unused\n", g)
+	}
+	return b.String()
+}
+
+// firstCommit returns when the file at path was first committed, as a Unix
+// time. It is a variable so tests can run without git.
+var firstCommit = gitFirstCommit
+
+func gitFirstCommit(path string) (int64, bool) {
+	cmd := exec.Command("git", "log", "--follow", "--diff-filter=A", "--format=%ct", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, false
+	}
+	lines := strings.Fields(string(out))
+	if len(lines) == 0 {
+		return 0, false
+	}
+	t, err := strconv.ParseInt(lines[len(lines)-1], 10, 64)
+	return t, err == nil
+}
+
+// renumberFile is an ADR file considered by PlanRenumber.
+type renumberFile struct {
+	adrFile
+	meta      Meta
+	keep      bool
+	committed int64
+	inGit     bool
+}
+
+// PlanRenumber finds ADR files that share an ID, files whose frontmatter id
+// or heading disagree with their file name, and gaps in sequential IDs. For
+// each shared ID one file keeps it (see RenumberOptions.Keep); the others
+// get the next free numbers, or for date and timestamp IDs a -2, -3, ...
+// suffix. Gaps are closed only with RenumberOptions.CloseGaps. Markdown
+// references to renamed files from any ADR are rewritten, and so are
+// frontmatter relations naming an ID that moved.
+func (m Manager) PlanRenumber(opt RenumberOptions) (RenumberPlan, error) {
+	found, err := findADRs(m.Dir, m.Config.ID)
+	if err != nil {
+		return RenumberPlan{}, err
+	}
+
+	ids := m.Config.ID.matcher()
+	var files []*renumberFile
+	taken := map[string]bool{}
+	max := 0
+	for _, a := range found {
+		meta, err := ParseADR(filepath.Join(m.Dir, filepath.FromSlash(a.File)))
+		var fe *FrontmatterError
+		if err != nil && !errors.As(err, &fe) {
+			return RenumberPlan{}, err
+		}
+		files = append(files, &renumberFile{adrFile: a, meta: meta})
+		taken[idKey(a.ID)] = true
+		if n := idNumber(a.ID); n > max {
+			max = n
+		}
+	}
+	for _, k := range opt.Keep {
+		matched := false
+		for _, f := range files {
+			if f.isNamed(k) {
+				f.keep, matched = true, true
+			}
+		}
+		if !matched {
+			return RenumberPlan{}, fmt.Errorf("--keep %s: no such ADR in %s", k, m.Dir)
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return entryLess(Entry{Number: idNumber(files[i].ID), ID: files[i].ID}, Entry{Number: idNumber(files[j].ID), ID: files[j].ID})
+	})
+	var groups [][]*renumberFile
+	for i, f := range files {
+		if i > 0 && idKey(f.ID) == idKey(files[i-1].ID) {
+			groups[len(groups)-1] = append(groups[len(groups)-1], f)
+		} else {
+			groups = append(groups, []*renumberFile{f})
+		}
+	}
+
+	var plan RenumberPlan
+	renamed := map[string]bool{}
+	for _, group := range groups {
+		if len(group) > 1 {
+			ranked, why, err := m.rankGroup(group)
+			if err != nil {
+				return RenumberPlan{}, err
+			}
+			winner := ranked[0]
+			for _, f := range ranked[1:] {
+				var id string
+				if m.Config.ID.Sequential() {
+					max++
+					id = m.Config.ID.FormatNumber(max)
+				} else {
+					id = suffixID(f.ID, taken)
+				}
+				taken[idKey(id)] = true
+				plan.Renumberings = append(plan.Renumberings, Renumbering{
+					File:    f.File,
+					NewFile: f.renamed(id),
+					OldID:   f.ID,
+					NewID:   id,
+					Reason:  fmt.Sprintf("%s is kept by %s, %s", f.ID, winner.File, why),
+				})
+				renamed[f.File] = true
+			}
+		}
+		for _, f := range group {
+			if !renamed[f.File] && f.meta.ID != "" && !ids.sameID(f.meta.ID, f.ID) {
+				old, _ := ids.entryID(f.meta.ID, f.meta.ID)
+				plan.Renumberings = append(plan.Renumberings, Renumbering{
+					File:    f.File,
+					NewFile: f.File,
+					OldID:   old,
+					NewID:   f.ID,
+					Reason:  "frontmatter id or heading disagrees with the file name",
+				})
+			}
+		}
+	}
+	if m.Config.ID.Sequential() {
+		m.planGaps(&plan, files, opt.CloseGaps)
+	}
+
+	// An ID still shared with the file that keeps it is ambiguous, so only
+	// relations naming an ID held by a single file follow it.
+	shared := map[string]bool{}
+	for _, group := range groups {
+		for _, f := range group {
+			shared[f.File] = len(group) > 1
+		}
+	}
+	moved := map[string]string{}
+	for _, r := range plan.Renumberings {
+		if r.NewFile != r.File && !shared[r.File] {
+			moved[r.OldID] = r.NewID
+		}
+	}
+
+	renames := plan.renames()
+	for _, f := range files {
+		content, err := os.ReadFile(filepath.Join(m.Dir, filepath.FromSlash(f.File)))
+		if err != nil {
+			return RenumberPlan{}, err
+		}
+		_, edits := rewriteFileRefs(content, f.File, renames)
+		for _, e := range edits {
+			e.File = f.File
+			plan.Links = append(plan.Links, e)
+		}
+		markup, _ := markupOf(f.File)
+		d, err := parseDocument(content, markup)
+		if err != nil {
+			continue // lint reports unreadable frontmatter
+		}
+		for _, e := range relationEdits(d, ids, moved) {
+			e.File = f.File
+			plan.Links = append(plan.Links, e)
+		}
+	}
+	return plan, nil
+}
+
+// planGaps finds unused numbers between sequential IDs, once the plan's
+// renumberings are made. With close, the ADRs after each gap move down to
+// fill it; otherwise the gaps are recorded in the plan.
+func (m Manager) planGaps(plan *RenumberPlan, files []*renumberFile, close bool) {
+	planned := map[string]int{}
+	for i, r := range plan.Renumberings {
+		planned[r.File] = i
+	}
+	type slot struct {
+		f *renumberFile
+		n int
+	}
+	var slots []slot
+	for _, f := range files {
+		id := f.ID
+		if i, ok := planned[f.File]; ok {
+			id = plan.Renumberings[i].NewID
+		}
+		slots = append(slots, slot{f, idNumber(id)})
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].n < slots[j].n })
+
+	next := 1
+	if len(slots) > 0 && slots[0].n == 0 {
+		next = 0 // a 0000 ADR starts the sequence
+	}
+	for _, s := range slots {
+		if s.n <= next {
+			next = s.n + 1
+			continue
+		}
+		if !close {
+			gap := m.Config.ID.FormatNumber(next)
+			if s.n-1 > next {
+				gap += "-" + m.Config.ID.FormatNumber(s.n-1)
+			}
+			plan.Gaps = append(plan.Gaps, gap)
+			next = s.n + 1
+			continue
+		}
+		id := m.Config.ID.FormatNumber(next)
+		next++
+		if i, ok := planned[s.f.File]; ok {
+			r := &plan.Renumberings[i]
+			if r.NewFile == r.File {
+				r.OldID, r.Reason = s.f.ID, "closes a gap in the sequence"
+			}
+			r.NewID, r.NewFile = id, s.f.renamed(id)
+			continue
+		}
+		plan.Renumberings = append(plan.Renumberings, Renumbering{
+			File:    s.f.File,
+			NewFile: s.f.renamed(id),
+			OldID:   s.f.ID,
+			NewID:   id,
+			Reason:  "closes a gap in the sequence",
+		})
+	}
+}
+
+// relationEdits returns the frontmatter relations of d that name an ID in
+// moved, which maps old to new IDs.
+func relationEdits(d *Document, ids idMatcher, moved map[string]string) []LinkEdit {
+	if len(moved) == 0 || !d.HasFrontmatter() {
+		return nil
+	}
+	first := bytes.Count(d.content[:d.fmStart], []byte("\n")) // lines before the frontmatter
+	var edits []LinkEdit
+	for _, kind := range RelationKinds {
+		n := d.Node(kind)
+		if n == nil {
+			continue
+		}
+		refs := n.Content
+		if n.Kind == yaml.ScalarNode {
+			refs = []*yaml.Node{n}
+		}
+		for _, ref := range refs {
+			for old, id := range moved {
+				if ref.Kind == yaml.ScalarNode && ref.Value != "" && ids.sameID(ref.Value, old) {
+					edits = append(edits, LinkEdit{Line: first + ref.Line, Key: kind, Old: ref.Value, New: id})
+					break
+				}
+			}
+		}
+	}
+	return edits
+}
+
+// rankGroup orders files sharing an ID: the first keeps it, and the
+// others are renumbered in order. It also says why the first was chosen.
+func (m Manager) rankGroup(group []*renumberFile) ([]*renumberFile, string, error) {
+	var kept []*renumberFile
+	for _, f := range group {
+		if f.keep {
+			kept = append(kept, f)
+		}
+		f.committed, f.inGit = firstCommit(filepath.Join(m.Dir, filepath.FromSlash(f.File)))
+	}
+	if len(kept) > 1 {
+		return nil, "", fmt.Errorf("--keep names both %s and %s, which share ADR number %s", kept[0].File, kept[1].File, group[0].ID)
+	}
+
+	sorted := append([]*renumberFile(nil), group...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch {
+		case a.keep != b.keep:
+			return a.keep
+		case a.inGit != b.inGit:
+			return a.inGit
+		case a.committed != b.committed:
+			return a.committed < b.committed
+		case a.meta.Date != b.meta.Date:
+			return a.meta.Date < b.meta.Date
+		}
+		return a.File < b.File
+	})
+	winner, runnerUp := sorted[0], sorted[1]
+	switch {
+	case winner.keep:
+		return sorted, "as requested", nil
+	case winner.inGit && (!runnerUp.inGit || winner.committed != runnerUp.committed):
+		return sorted, "which was committed first", nil
+	case winner.meta.Date != runnerUp.meta.Date:
+		return sorted, "which has the earliest date", nil
+	}
+	return sorted, "which sorts first", nil
+}
+
+// isNamed reports whether the --keep argument k names the ADR: its path
+// relative to the ADR directory, or any path ending in it. A directory-form
+// ADR can also be named by its directory.
+func (f *renumberFile) isNamed(k string) bool {
+	k = "/" + strings.TrimSuffix(filepath.ToSlash(filepath.Clean(k)), "/")
+	if strings.HasSuffix(k, "/"+f.File) {
+		return true
+	}
+	return f.Bundle && strings.HasSuffix(k, "/"+path.Dir(f.File))
+}
+
+// renamed returns the path of the ADR once its ID is changed to id.
+func (f *renumberFile) renamed(id string) string {
+	name := path.Join(f.Category, id+f.Rest)
+	if f.Bundle {
+		return path.Join(name, path.Base(f.File))
+	}
+	return name
+}
+
+// renamedPart returns the file or bundle directory that is renamed when an
+// ADR moves from file to newFile: its parent, relative to the ADR
+// directory, and its old and new names.
+func renamedPart(file, newFile string) (dir, old, new string) {
+	if path.Base(file) == path.Base(newFile) {
+		file, newFile = path.Dir(file), path.Dir(newFile)
+	}
+	return path.Dir(file), path.Base(file), path.Base(newFile)
+}
+
+// renames maps the old to the new path, relative to the ADR directory, of
+// every file or bundle directory that is renamed. Paths rather than names
+// tell apart ADRs with the same name in two categories or sources.
+func (p RenumberPlan) renames() map[string]string {
+	renames := map[string]string{}
+	for _, r := range p.Renumberings {
+		if r.NewFile != r.File {
+			dir, old, new := renamedPart(r.File, r.NewFile)
+			renames[path.Join(dir, old)] = path.Join(dir, new)
+		}
+	}
+	return renames
+}
+
+// ApplyRenumber carries out a plan from PlanRenumber. Every edit is worked
+// out before any file changes; if renaming or writing then fails, the
+// changes already made are undone.
+func (m Manager) ApplyRenumber(p RenumberPlan) error {
+	renames := p.renames()
+	ids := map[string]Renumbering{}
+	for _, r := range p.Renumberings {
+		ids[r.File] = r
+	}
+	relations := map[string][]LinkEdit{}
+	files := map[string]bool{}
+	for _, l := range p.Links {
+		files[l.File] = true
+		if l.Key != "" {
+			relations[l.File] = append(relations[l.File], l)
+		}
+	}
+	for f := range ids {
+		files[f] = true
+	}
+
+	type write struct {
+		path     string // where the file is once renamed
+		old, new []byte
+	}
+	var writes []write
+	for name := range files {
+		path := filepath.Join(m.Dir, filepath.FromSlash(name))
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content, _ := rewriteFileRefs(old, name, renames)
+		r, renumbered := ids[name]
+		if renumbered || len(relations[name]) > 0 {
+			markup, _ := markupOf(name)
+			d, err := parseDocument(content, markup)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if err := setRelations(d, relations[name]); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if renumbered {
+				if err := setDocumentID(d, r.NewID); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				path = filepath.Join(m.Dir, filepath.FromSlash(r.NewFile))
+			}
+			content = d.Bytes()
+		}
+		writes = append(writes, write{path, old, content})
+	}
+
+	var undo []func()
+	fail := func(err error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		return err
+	}
+	rename := func(from, to string) error {
+		if _, err := os.Lstat(to); err == nil {
+			return fmt.Errorf("renumber: %s already exists", to)
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+		undo = append(undo, func() { os.Rename(to, from) })
+		return nil
+	}
+	// Renaming through temporary names lets ADRs move into numbers that
+	// other ADRs are leaving.
+	type move struct{ tmp, to string }
+	var moves []move
+	for i, r := range p.Renumberings {
+		if r.NewFile == r.File {
+			continue
+		}
+		dir, old, new := renamedPart(r.File, r.NewFile)
+		dir = filepath.Join(m.Dir, filepath.FromSlash(dir))
+		tmp := filepath.Join(dir, fmt.Sprintf(".renumber-%d-%s", i, old))
+		if err := rename(filepath.Join(dir, old), tmp); err != nil {
+			return fail(err)
+		}
+		moves = append(moves, move{tmp, filepath.Join(dir, new)})
+	}
+	for _, mv := range moves {
+		if err := rename(mv.tmp, mv.to); err != nil {
+			return fail(err)
+		}
+	}
+	for _, w := range writes {
+		changed, err := writeFileIfChanged(w.path, w.new)
+		if err != nil {
+			return fail(err)
+		}
+		if changed {
+			path, old := w.path, w.old
+			undo = append(undo, func() { writeFileAtomic(path, old) })
+		}
+	}
+	return nil
+}
+
+// setRelations rewrites the frontmatter relation references in edits.
+func setRelations(d *Document, edits []LinkEdit) error {
+	byKey := map[string]map[string]string{}
+	for _, e := range edits {
+		if byKey[e.Key] == nil {
+			byKey[e.Key] = map[string]string{}
+		}
+		byKey[e.Key][e.Old] = e.New
+	}
+	for key, ids := range byKey {
+		n := d.Node(key)
+		if n == nil {
+			continue
+		}
+		if n.Kind == yaml.ScalarNode {
+			if id, ok := ids[n.Value]; ok {
+				if err := d.SetNode(key, &yaml.Node{Kind: yaml.ScalarNode, Value: id}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		var list []string
+		for _, ref := range n.Content {
+			if id, ok := ids[ref.Value]; ok {
+				list = append(list, id)
+			} else {
+				list = append(list, ref.Value)
+			}
+		}
+		if err := d.Set(key, list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var reTitleID = regexp.MustCompile(`(?i)^(\s*[#=]*\s*ADR\s+)([0-9A-Za-z][0-9A-Za-z._-]*)(\s*:)`)
+
+// setDocumentID sets the frontmatter id and the number in the "# ADR NNNN:"
+// heading of an ADR. A reStructuredText title adornment is lengthened when
+// the title outgrows it.
+func setDocumentID(d *Document, id string) error {
+	if d.HasFrontmatter() {
+		// a plain scalar keeps the id unquoted, as the templates write it
+		if err := d.SetNode("id", &yaml.Node{Kind: yaml.ScalarNode, Value: id}); err != nil {
+			return err
+		}
+	}
+	lines := strings.SplitAfter(string(d.Body()), "\n")
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i], _ = splitEOL(line)
+	}
+	for i, text := range markdownLines(d.markup, texts) {
+		if !reADRTitle.MatchString(text) {
+			continue
+		}
+		loc := reTitleID.FindStringSubmatchIndex(texts[i])
+		if loc == nil {
+			return nil
+		}
+		title := texts[i][:loc[4]] + id + texts[i][loc[5]:]
+		lines[i] = title + lines[i][len(texts[i]):]
+		if d.markup == MarkupRST {
+			for _, j := range []int{i - 1, i + 1} {
+				if j < 0 || j >= len(lines) {
+					continue
+				}
+				adornment := strings.TrimRight(texts[j], " \t")
+				if isAdornment(adornment) && textWidth(adornment) < textWidth(strings.TrimSpace(title)) {
+					lines[j] = underline(adornment[:1], strings.TrimSpace(title)) + lines[j][len(texts[j]):]
+				}
+			}
+		}
+		return d.SetBody([]byte(strings.Join(lines, "")))
+	}
+	return nil
+}
+
+// rewriteFileRefs replaces references to renamed files, such as the targets
+// of markdown links, in the file from (relative to the ADR directory), and
+// returns the new content and the edits made. A reference is resolved
+// against the directory of from before it is matched with renames.
+func rewriteFileRefs(content []byte, from string, renames map[string]string) ([]byte, []LinkEdit) {
+	if len(renames) == 0 {
+		return content, nil
+	}
+	olds := make([]string, 0, len(renames))
+	for old := range renames {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+
+	var edits []LinkEdit
+	lines := strings.SplitAfter(string(content), "\n")
+	for i, line := range lines {
+		var refs []fileRef
+		for _, old := range olds {
+			for _, ref := range findFileRefs(line, from, old) {
+				ref.new = path.Base(renames[old])
+				refs = append(refs, ref)
+				edits = append(edits, LinkEdit{Line: i + 1, Old: path.Base(old), New: ref.new})
+			}
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		// replace in one pass, so a new name is not taken for an old one
+		sort.Slice(refs, func(a, b int) bool { return refs[a].start < refs[b].start })
+		var b strings.Builder
+		last := 0
+		for _, ref := range refs {
+			b.WriteString(line[last:ref.start])
+			b.WriteString(ref.new)
+			last = ref.end
+		}
+		b.WriteString(line[last:])
+		lines[i] = b.String()
+	}
+	return []byte(strings.Join(lines, "")), edits
+}
+
+// fileRef is the name part of a reference to a renamed file in a line.
+type fileRef struct {
+	start, end int
+	new        string
+}
+
+// findFileRefs returns the references in line, in the file from, that
+// resolve to the file or directory old, relative to the ADR directory. The
+// name must not be part of a longer name, and may be preceded by a
+// relative path such as ../platform/.
+func findFileRefs(line, from, old string) []fileRef {
+	name := path.Base(old)
+	var refs []fileRef
+	for at := 0; ; {
+		i := strings.Index(line[at:], name)
+		if i < 0 {
+			return refs
+		}
+		i += at
+		end := i + len(name)
+		at = end
+		if i > 0 && isNameByte(line[i-1]) || continuesName(line[end:]) {
+			continue
+		}
+		start := i
+		for start > 0 && (line[start-1] == '/' || isNameByte(line[start-1])) {
+			start--
+		}
+		ref := line[start:end]
+		if strings.HasPrefix(ref, "/") || path.Join(path.Dir(from), ref) != old {
+			continue
+		}
+		refs = append(refs, fileRef{start: i, end: end})
+	}
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '-' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// continuesName reports whether rest, the text after a file name, makes it
+// part of a longer name. A dot ending a sentence does not.
+func continuesName(rest string) bool {
+	if rest == "" || !isNameByte(rest[0]) {
+		return false
+	}
+	return rest[0] != '.' || (len(rest) > 1 && isNameByte(rest[1]) && rest[1] != '.')
+}
@@ -0,0 +1,311 @@
+package adr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRenumber verifies colliding files are renumbered in commit order,
+// mismatched ids are fixed and links to renamed files are rewritten.
+func TestRenumber(t *testing.T) {
+	commits := map[string]int64{"0002-use-zig.md": 100, "0002-use-rust.md": 200}
+	firstCommit = func(path string) (int64, bool) {
+		c, ok := commits[filepath.Base(path)]
+		return c, ok
+	}
+	defer func() { firstCommit = gitFirstCommit }()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"0001-use-go.md":   "---\nid: 7\ntitle: \"Use Go\"\n---\n\n# ADR 0001: Use Go\n\nSee [Rust](./0002-use-rust.md#context) and [Zig](0002-use-zig.md).\n",
+		"0002-use-rust.md": "---\nid: 0002\ntitle: \"Use Rust\"\n---\n\n# ADR 0002: Use Rust\n",
+		"0002-use-zig.md":  "---\nid: 0002\ntitle: \"Use Zig\"\n---\n\n# ADR 0002: Use Zig\n\nNot [0002-use-rust.md.bak](x0002-use-rust.md).\n",
+		"0002-use-c.md":    "# ADR 0002: Use C\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := Manager{Dir: dir}
+	plan, err := m.PlanRenumber(RenumberOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0001-use-go.md: id 0007 -> 0001 (frontmatter id or heading disagrees with the file name)\n" +
+		"0002-use-rust.md -> 0003-use-rust.md (0002 is kept by 0002-use-zig.md, which was committed first)\n" +
+		"0002-use-c.md -> 0004-use-c.md (0002 is kept by 0002-use-zig.md, which was committed first)\n" +
+		"0001-use-go.md:8: link 0002-use-rust.md -> 0003-use-rust.md\n"
+	if got := plan.String(); got != want {
+		t.Errorf("plan:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := m.ApplyRenumber(plan); err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if got := read("0001-use-go.md"); !strings.Contains(got, "id: 0001\n") || !strings.Contains(got, "[Rust](./0003-use-rust.md#context) and [Zig](0002-use-zig.md)") {
+		t.Errorf("0001 not updated:\n%s", got)
+	}
+	if got := read("0003-use-rust.md"); got != "---\nid: 0003\ntitle: \"Use Rust\"\n---\n\n# ADR 0003: Use Rust\n" {
+		t.Errorf("0003 = %q", got)
+	}
+	if got := read("0004-use-c.md"); got != "# ADR 0004: Use C\n" {
+		t.Errorf("0004 = %q", got)
+	}
+	if got := read("0002-use-zig.md"); got != files["0002-use-zig.md"] {
+		t.Errorf("kept file changed: %q", got)
+	}
+
+	if plan, err := m.PlanRenumber(RenumberOptions{}); err != nil || !plan.Empty() {
+		t.Errorf("second plan not empty: %v %v", plan, err)
+	}
+}
+
+// TestRenumberCategories verifies links follow only the renamed ADR when
+// ADRs with the same file name live in two categories.
+func TestRenumberCategories(t *testing.T) {
+	firstCommit = func(path string) (int64, bool) {
+		if strings.Contains(filepath.ToSlash(path), "security/") {
+			return 200, true
+		}
+		return 100, true
+	}
+	defer func() { firstCommit = gitFirstCommit }()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"0001-x.md":          "# ADR 0001: X\n\nSee [platform](platform/0003-a.md) and [security](security/0003-a.md).\n",
+		"0002-y.md":          "# ADR 0002: Y\n",
+		"platform/0003-a.md": "# ADR 0003: A\n\nSee [ours](0003-a.md) and [theirs](../security/0003-a.md).\n",
+		"security/0003-a.md": "# ADR 0003: A\n\nSee [ours](./0003-a.md) and [theirs](../platform/0003-a.md).\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := Manager{Dir: dir}
+	plan, err := m.PlanRenumber(RenumberOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ApplyRenumber(plan); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"0001-x.md":          "# ADR 0001: X\n\nSee [platform](platform/0003-a.md) and [security](security/0004-a.md).\n",
+		"platform/0003-a.md": "# ADR 0003: A\n\nSee [ours](0003-a.md) and [theirs](../security/0004-a.md).\n",
+		"security/0004-a.md": "# ADR 0004: A\n\nSee [ours](./0004-a.md) and [theirs](../platform/0003-a.md).\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+}
+
+// TestRenumberKeep verifies --keep overrides commit order.
+func TestRenumberKeep(t *testing.T) {
+	firstCommit = func(string) (int64, bool) { return 0, false }
+	defer func() { firstCommit = gitFirstCommit }()
+
+	dir := t.TempDir()
+	for _, name := range []string{"0001-a.md", "0001-b.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# ADR 0001: X\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := Manager{Dir: dir}
+	plan, err := m.PlanRenumber(RenumberOptions{Keep: []string{filepath.Join(dir, "0001-b.md")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Renumberings) != 1 || plan.Renumberings[0].File != "0001-a.md" || plan.Renumberings[0].NewFile != "0002-a.md" {
+		t.Errorf("plan = %+v", plan.Renumberings)
+	}
+	if _, err := m.PlanRenumber(RenumberOptions{Keep: []string{"0001-a.md", "0001-b.md"}}); err == nil {
+		t.Error("keeping both colliding files should fail")
+	}
+	if _, err := m.PlanRenumber(RenumberOptions{Keep: []string{"0009-missing.md"}}); err == nil {
+		t.Error("keeping a missing file should fail")
+	}
+}
+
+// TestRenumberLeadingZeroIDs verifies ADRs written by the templates, whose
+// ids such as 0010 are unquoted, need no renumbering.
+func TestRenumberLeadingZeroIDs(t *testing.T) {
+	firstCommit = func(string) (int64, bool) { return 0, false }
+	defer func() { firstCommit = gitFirstCommit }()
+
+	m := Manager{Dir: t.TempDir()}
+	for i := 1; i <= 12; i++ {
+		if _, err := m.WriteNewADR(fmt.Sprintf("Decision %d", i), NewOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan, err := m.PlanRenumber(RenumberOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() || len(plan.Gaps) > 0 {
+		t.Errorf("plan not empty:\n%s", plan)
+	}
+}
+
+// writeSequence writes ADRs with the given numbers; extra adds frontmatter
+// lines and body text per number.
+func writeSequence(t *testing.T, dir string, numbers []int, extra map[int][2]string) {
+	t.Helper()
+	for _, n := range numbers {
+		adr := fmt.Sprintf("---\nid: %04d\ntitle: \"ADR %d\"\nstatus: \"Accepted\"\ndate: \"2025-01-15\"\n%s---\n\n# ADR %04d: ADR %d\n%s", n, n, extra[n][0], n, n, extra[n][1])
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%04d-adr-%d.md", n, n)), []byte(adr), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestRenumberGaps verifies gaps are reported, and closed on request with
+// frontmatter relations and links following the moved ADRs.
+func TestRenumberGaps(t *testing.T) {
+	firstCommit = func(string) (int64, bool) { return 0, false }
+	defer func() { firstCommit = gitFirstCommit }()
+
+	dir := t.TempDir()
+	numbers := []int{1, 2, 3, 6, 7, 8, 9, 10, 11, 12, 13}
+	writeSequence(t, dir, numbers, map[int][2]string{
+		2: {"relates_to: [3, 0012]\n", ""},
+		7: {"supersedes: [0006]\n", ""},
+		8: {"superseded_by: 0007\n", "\nSee [ADR 10](./0010-adr-10.md).\n"},
+	})
+	m := Manager{Dir: dir}
+
+	plan, err := m.PlanRenumber(RenumberOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() || strings.Join(plan.Gaps, ",") != "0004-0005" {
+		t.Errorf("gaps should only be reported:\n%s", plan)
+	}
+
+	plan, err = m.PlanRenumber(RenumberOptions{CloseGaps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Renumberings) != 8 || len(plan.Gaps) != 0 {
+		t.Fatalf("plan:\n%s", plan)
+	}
+	for _, want := range []string{
+		"0006-adr-6.md -> 0004-adr-6.md (closes a gap in the sequence)\n",
+		"0013-adr-13.md -> 0011-adr-13.md (closes a gap in the sequence)\n",
+		"0002-adr-2.md:6: relates_to 0012 -> 0010\n",
+		"0007-adr-7.md:6: supersedes 0006 -> 0004\n",
+		"0008-adr-8.md:6: superseded_by 0007 -> 0005\n",
+		"0008-adr-8.md:11: link 0010-adr-10.md -> 0008-adr-10.md\n",
+	} {
+		if !strings.Contains(plan.String(), want) {
+			t.Errorf("plan lacks %q:\n%s", want, plan)
+		}
+	}
+
+	if err := m.ApplyRenumber(plan); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range entries {
+		if e.Number != i+1 || e.ID != fmt.Sprintf("%04d", i+1) {
+			t.Errorf("%s: got ID %s, want %04d", e.File, e.ID, i+1)
+		}
+	}
+	for _, c := range [][2]string{
+		{"0002-adr-2.md", "relates_to: [3, 0010]\n"},
+		{"0005-adr-7.md", "id: 0005\n"},
+		{"0005-adr-7.md", "supersedes: [0004]\n"},
+		{"0006-adr-8.md", "superseded_by: 0005\n"},
+		{"0006-adr-8.md", "See [ADR 10](./0008-adr-10.md).\n"},
+		{"0008-adr-10.md", "# ADR 0008: ADR 10\n"},
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, c[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), c[1]) {
+			t.Errorf("%s lacks %q:\n%s", c[0], c[1], b)
+		}
+	}
+	if plan, err := m.PlanRenumber(RenumberOptions{CloseGaps: true}); err != nil || !plan.Empty() {
+		t.Errorf("second plan not empty: %v %v", plan, err)
+	}
+}
+
+// TestRenumberRollback verifies a failure part way through ApplyRenumber
+// leaves every file as it was.
+func TestRenumberRollback(t *testing.T) {
+	firstCommit = func(string) (int64, bool) { return 0, false }
+	defer func() { firstCommit = gitFirstCommit }()
+
+	dir := t.TempDir()
+	writeSequence(t, dir, []int{1, 3, 4}, map[int][2]string{
+		1: {"", "\nSee [ADR 3](0003-adr-3.md).\n"},
+		4: {"supersedes: 3\n", ""},
+	})
+	snapshot := func() map[string]string {
+		files := map[string]string{}
+		items, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, it := range items {
+			b, err := os.ReadFile(filepath.Join(dir, it.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[it.Name()] = string(b)
+		}
+		return files
+	}
+
+	m := Manager{Dir: dir}
+	plan, err := m.PlanRenumber(RenumberOptions{CloseGaps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0003 moves to 0002 first; then the move of 0004 to 0003 is blocked.
+	if err := os.WriteFile(filepath.Join(dir, "0003-adr-4.md"), []byte("in the way\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before := snapshot()
+	if err := m.ApplyRenumber(plan); err == nil {
+		t.Fatal("ApplyRenumber should fail when a new name is taken")
+	}
+	after := snapshot()
+	if len(after) != len(before) {
+		t.Errorf("files after rollback: %v, want %v", after, before)
+	}
+	for name, content := range before {
+		if after[name] != content {
+			t.Errorf("%s changed by a failed renumber:\n%s", name, after[name])
+		}
+	}
+}
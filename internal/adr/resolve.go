@@ -0,0 +1,71 @@
+package adr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Successors returns the ADRs that directly supersede e. Links are honored
+// from either side, so an ADR declaring "supersedes: e" counts even when e
+// itself has no superseded_by entry. superseded_by ids that name no ADR are
+// skipped; Resolve reports them.
+func Successors(entries []Entry, e Entry) []Entry {
+	var out []Entry
+	seen := map[string]bool{}
+	add := func(s Entry) {
+		if s.File == e.File || seen[s.File] {
+			return
+		}
+		seen[s.File] = true
+		out = append(out, s)
+	}
+	for _, id := range e.SupersededBy {
+		if s, ok := Lookup(entries, id); ok {
+			add(s)
+		}
+	}
+	for _, s := range entries {
+		if s.Supersedes.Contains(e.ID) {
+			add(s)
+		}
+	}
+	return out
+}
+
+// Resolve follows the supersession chain from the ADR with the given id. The
+// returned chain starts with that ADR and ends with the decision currently in
+// effect. It fails on dangling ids, cycles, and ADRs superseded by more than
+// one decision, since there is no single answer in those cases.
+func Resolve(entries []Entry, id string) ([]Entry, error) {
+	e, ok := Lookup(entries, id)
+	if !ok {
+		return nil, fmt.Errorf("ADR %s not found", id)
+	}
+	chain := []Entry{e}
+	visited := map[string]bool{e.File: true}
+	for {
+		for _, id := range e.SupersededBy {
+			if _, ok := Lookup(entries, id); !ok {
+				return chain, fmt.Errorf("ADR %s is superseded by ADR %s, which does not exist", e.ID, id)
+			}
+		}
+		next := Successors(entries, e)
+		switch len(next) {
+		case 0:
+			return chain, nil
+		case 1:
+		default:
+			ids := make([]string, len(next))
+			for i, n := range next {
+				ids[i] = n.ID
+			}
+			return chain, fmt.Errorf("ADR %s is superseded by multiple ADRs: %s", e.ID, strings.Join(ids, ", "))
+		}
+		e = next[0]
+		if visited[e.File] {
+			return chain, fmt.Errorf("supersession cycle detected at ADR %s", e.ID)
+		}
+		visited[e.File] = true
+		chain = append(chain, e)
+	}
+}
@@ -0,0 +1,115 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRelations(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "0004-relations.md")
+	content := `---
+id: 4
+title: "Relations"
+status: "Accepted"
+date: "2025-01-15"
+supersedes: 2
+amends: [0001]
+depends_on: [3, ADR-0005]
+relates_to:
+  - 6
+---
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+
+	checks := map[string]string{
+		"supersedes": "0002",
+		"amends":     "1",
+		"depends_on": "5",
+		"relates_to": "0006",
+	}
+	for kind, id := range checks {
+		if !e.Get(kind).Contains(id) {
+			t.Errorf("%s: expected %s in %v", kind, id, e.Get(kind))
+		}
+	}
+	if len(e.DependsOn) != 2 {
+		t.Errorf("depends_on: expected 2 refs, got %v", e.DependsOn)
+	}
+	if len(e.SupersededBy) != 0 || len(e.AmendedBy) != 0 {
+		t.Errorf("unexpected inverse relations: %+v", e.Relations)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	entries := []Entry{
+		{Number: 1, ID: "0001", File: "0001-a.md", Relations: Relations{SupersededBy: IDList{"2"}}},
+		{Number: 2, ID: "0002", File: "0002-b.md", Relations: Relations{Supersedes: IDList{"1"}}},
+		// 0003 only records the link on the new side.
+		{Number: 3, ID: "0003", File: "0003-c.md", Relations: Relations{Supersedes: IDList{"0002"}}},
+		{Number: 4, ID: "0004", File: "0004-d.md"},
+	}
+
+	chain, err := Resolve(entries, "1")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if got := joinIDs(chain); got != "0001,0002,0003" {
+		t.Errorf("chain: got %s", got)
+	}
+
+	chain, err = Resolve(entries, "4")
+	if err != nil || joinIDs(chain) != "0004" {
+		t.Errorf("unsuperseded ADR should resolve to itself, got %s, %v", joinIDs(chain), err)
+	}
+
+	if _, err := Resolve(entries, "9"); err == nil {
+		t.Error("expected error for unknown ADR")
+	}
+
+	forked := append(entries, Entry{Number: 5, ID: "0005", File: "0005-e.md", Relations: Relations{Supersedes: IDList{"3"}}},
+		Entry{Number: 6, ID: "0006", File: "0006-f.md", Relations: Relations{Supersedes: IDList{"3"}}})
+	if _, err := Resolve(forked, "1"); err == nil {
+		t.Error("expected error when superseded by multiple ADRs")
+	}
+
+	dangling := []Entry{
+		{Number: 1, ID: "0001", File: "0001-a.md", Relations: Relations{SupersededBy: IDList{"2"}}},
+		{Number: 2, ID: "0002", File: "0002-b.md", Relations: Relations{SupersededBy: IDList{"7"}}},
+	}
+	if chain, err := Resolve(dangling, "1"); err == nil || !strings.Contains(err.Error(), "ADR 7") {
+		t.Errorf("expected error for a dangling superseded_by id, got %s, %v", joinIDs(chain), err)
+	}
+
+	cycle := []Entry{
+		{Number: 1, ID: "0001", File: "0001-a.md", Relations: Relations{SupersededBy: IDList{"2"}}},
+		{Number: 2, ID: "0002", File: "0002-b.md", Relations: Relations{SupersededBy: IDList{"1"}}},
+	}
+	if _, err := Resolve(cycle, "1"); err == nil {
+		t.Error("expected error for supersession cycle")
+	}
+}
+
+func joinIDs(entries []Entry) string {
+	s := ""
+	for i, e := range entries {
+		if i > 0 {
+			s += ","
+		}
+		s += e.ID
+	}
+	return s
+}
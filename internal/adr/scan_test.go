@@ -81,3 +81,87 @@ func TestScanFiltersNonADR(t *testing.T) {
 		t.Errorf("Malformed ADR fallback: expected non-empty Title")
 	}
 }
+
+// TestScanRecursive verifies that Scan finds ADRs in category
+// subdirectories and directory-form ADRs, skips drafts, hidden directories
+// and bundle assets, and that new ADRs are numbered across all of them.
+func TestScanRecursive(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	write("0001-use-go.md", "---\nid: 1\ntitle: Use Go\n---\n")
+	write("platform/0002-use-grpc.md", "---\nid: 2\ntitle: Use gRPC\n---\n")
+	write("platform/README.md", "# Platform decisions\n")
+	write("0003-event-bus/README.md", "# ADR 0003: Event bus\n")
+	write("0003-event-bus/0009-diagram.md", "not an ADR\n")
+	write("security/authn/0004-use-oidc/index.md", "---\nid: 4\n---\n")
+	write("drafts/0005-draft.md", "---\nid: 5\n---\n")
+	write(".git/0006-object.md", "---\nid: 6\n---\n")
+	write("0007-assets/diagram.svg", "<svg/>")
+
+	entries, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{ID: "0001", Title: "Use Go", File: "0001-use-go.md"},
+		{ID: "0002", Title: "Use gRPC", File: "platform/0002-use-grpc.md", Category: "platform"},
+		{ID: "0003", Title: "Event bus", File: "0003-event-bus/README.md"},
+		{ID: "0004", Title: "use oidc", File: "security/authn/0004-use-oidc/index.md", Category: "security/authn"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.ID != w.ID || e.Title != w.Title || e.File != w.File || e.Category != w.Category {
+			t.Errorf("entry %d: got %s %q %s [%s], want %s %q %s [%s]", i, e.ID, e.Title, e.File, e.Category, w.ID, w.Title, w.File, w.Category)
+		}
+	}
+
+	m := Manager{Dir: dir}
+	path, err := m.WriteNewADR("Store assets", NewOptions{Bundle: true, Category: "platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "platform", "0005-store-assets", "README.md"); path != want {
+		t.Errorf("bundle: got %s, want %s", path, want)
+	}
+	if _, err := m.WriteNewADR("Escape", NewOptions{Category: "../elsewhere"}); err == nil {
+		t.Error("expected a category outside the ADR directory to be rejected")
+	}
+}
+
+// TestScanNumericCategory verifies a category directory named like a
+// number, with a README.md, is walked rather than read as a bundle ADR.
+func TestScanNumericCategory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"2024/README.md":        "# Decisions made in 2024\n",
+		"2024/0001-use-go.md":   "---\nid: 1\ntitle: Use Go\n---\n",
+		"2024/0002-use-grpc.md": "---\nid: 2\ntitle: Use gRPC\n---\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].File != "2024/0001-use-go.md" || entries[1].File != "2024/0002-use-grpc.md" || entries[0].Category != "2024" {
+		t.Errorf("entries = %+v, want the two ADRs in category 2024", entries)
+	}
+}
@@ -0,0 +1,242 @@
+package adr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Section is a node of an ADR's outline: a markdown heading or a bold label
+// such as **Context:**, and the text under it.
+type Section struct {
+	Title string // heading or label text as written, without markup
+	Name  string // canonical name, e.g. "context"; see SectionName
+	Level int    // heading level; labels are one below their parent; 0 for the root
+	Label bool   // true for **Label:** sections
+	Line  int    // 1-based line of the heading in the file
+
+	// Text is everything under the heading up to the next heading of the
+	// same or a higher level, including subsections. For a label with text
+	// on the same line, Text starts after the label.
+	Text string
+	// Content is the part of Text before the first subsection.
+	Content  string
+	Children []*Section
+
+	head       int // offset of the line holding the heading
+	start, end int // offsets of Text in the document
+}
+
+// sectionAliases maps alternative titles used by the MADR, Nygard and
+// other common templates to a canonical section name.
+var sectionAliases = map[string]string{
+	"context-and-problem-statement": "context",
+	"problem-statement":             "context",
+	"background":                    "context",
+	"decision-outcome":              "decision",
+	"other-options-considered":      "considered-options",
+	"options-considered":            "considered-options",
+	"alternatives":                  "considered-options",
+	"alternatives-considered":       "considered-options",
+	"options":                       "considered-options",
+	"implications":                  "consequences",
+	"positive-consequences":         "consequences-positive",
+	"negative-consequences":         "consequences-negative",
+	"pros-and-cons-of-the-options":  "pros-and-cons",
+	"date-proposed":                 "date",
+	"deciders":                      "participants",
+	"references":                    "links",
+}
+
+// SectionName returns the canonical name of a section title: the title in
+// lower-case kebab form, mapped through known aliases, so "Context and
+// Problem Statement", "Context" and "**Context:**" are all "context".
+func SectionName(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	slug := b.String()
+	if name, ok := sectionAliases[slug]; ok {
+		return name
+	}
+	return slug
+}
+
+var (
+	reHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	reLabel   = regexp.MustCompile(`^\*\*([^*]+?)\*\*(.*)$`)
+
+	reHTMLComment = regexp.MustCompile(`<!--.*?-->`)
+)
+
+// parseLabel recognizes a bold label line: "**Context:**", "**Context**:"
+// followed by optional text, or a bold phrase alone on its line. It returns
+// the label and the length of the label markup including trailing spaces.
+func parseLabel(line string) (string, int, bool) {
+	g := reLabel.FindStringSubmatchIndex(line)
+	if g == nil {
+		return "", 0, false
+	}
+	label, rest := line[g[2]:g[3]], line[g[3]+2:]
+	n := g[3] + 2
+	switch {
+	case strings.HasSuffix(label, ":"):
+		label = strings.TrimSuffix(label, ":")
+	case strings.HasPrefix(rest, ":"):
+		n++
+		rest = rest[1:]
+	case strings.TrimSpace(rest) != "":
+		return "", 0, false // bold text inside a sentence
+	}
+	n += len(rest) - len(strings.TrimLeft(rest, " \t"))
+	return strings.TrimSpace(label), n, true
+}
+
+// Outline parses the body into a tree of sections. The root has level 0 and
+// spans the whole body; the "# ADR NNNN: Title" heading is named "title".
+// Headings and labels inside fenced code blocks are ignored. AsciiDoc and
+// reStructuredText headings are read as their markdown equivalents.
+func (d *Document) Outline() *Section {
+	root := &Section{start: d.body, end: len(d.content)}
+	open := []*Section{root}
+	closeTo := func(off int, keep func(*Section) bool) {
+		for len(open) > 1 && !keep(open[len(open)-1]) {
+			open[len(open)-1].end = off
+			open = open[:len(open)-1]
+		}
+	}
+
+	lines := strings.SplitAfter(string(d.content[d.body:]), "\n")
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i], _ = splitEOL(line)
+	}
+	fence := ""
+	off := d.body
+	lineNo := strings.Count(string(d.content[:d.body]), "\n")
+	for i, text := range markdownLines(d.markup, texts) {
+		line := lines[i]
+		lineNo++
+		if f := codeFence(text); f != "" {
+			switch {
+			case fence == "":
+				fence = f
+			case strings.HasPrefix(f, fence):
+				fence = ""
+			}
+		}
+		if fence == "" {
+			if g := reHeading.FindStringSubmatch(text); g != nil {
+				level := len(g[1])
+				head, start := off, off
+				if d.markup == MarkupRST && i > 0 && isAdornment(strings.TrimRight(texts[i-1], " \t")) {
+					head -= len(lines[i-1]) // a reStructuredText overline
+				}
+				for j := i; j <= headingEnd(d.markup, texts, i); j++ {
+					start += len(lines[j])
+				}
+				closeTo(head, func(s *Section) bool { return s.Level < level })
+				// a trailing comment, as in "## Links <!-- optional -->", is not part of the title
+				title := strings.TrimSpace(reHTMLComment.ReplaceAllString(g[2], ""))
+				s := &Section{Title: title, Level: level, Line: lineNo, head: head, start: start}
+				parent := open[len(open)-1]
+				parent.Children = append(parent.Children, s)
+				open = append(open, s)
+			} else if label, n, ok := parseLabel(text); ok {
+				closeTo(off, func(s *Section) bool { return !s.Label })
+				parent := open[len(open)-1]
+				s := &Section{Title: label, Level: parent.Level + 1, Label: true, Line: lineNo, head: off, start: off + n}
+				if strings.TrimSpace(text[n:]) == "" {
+					s.start = off + len(line)
+				}
+				parent.Children = append(parent.Children, s)
+				open = append(open, s)
+			}
+		}
+		off += len(line)
+	}
+	closeTo(len(d.content), func(*Section) bool { return false })
+
+	var fill func(s *Section)
+	fill = func(s *Section) {
+		s.Name = SectionName(s.Title)
+		if s.Level == 1 && reADRTitle.MatchString("# "+s.Title) {
+			s.Name = "title"
+		}
+		s.Text = string(d.content[s.start:s.end])
+		s.Content = s.Text
+		if len(s.Children) > 0 {
+			if i := s.Children[0].head; i >= s.start && i <= s.end {
+				s.Content = string(d.content[s.start:i])
+			}
+		}
+		for _, c := range s.Children {
+			fill(c)
+		}
+	}
+	fill(root)
+	root.Name = ""
+	return root
+}
+
+// Sections returns every section of the outline in document order.
+func (d *Document) Sections() []*Section {
+	var out []*Section
+	d.Outline().Walk(func(s *Section) {
+		out = append(out, s)
+	})
+	return out
+}
+
+// Walk calls fn for every descendant of s in document order.
+func (s *Section) Walk(fn func(*Section)) {
+	for _, c := range s.Children {
+		fn(c)
+		c.Walk(fn)
+	}
+}
+
+// Find returns the first descendant whose canonical name matches name
+// (itself canonicalized), or nil.
+func (s *Section) Find(name string) *Section {
+	want := SectionName(name)
+	var found *Section
+	s.Walk(func(c *Section) {
+		if found == nil && c.Name == want {
+			found = c
+		}
+	})
+	return found
+}
+
+// Section returns the first section matching name; see Section.Find.
+func (d *Document) Section(name string) (*Section, bool) {
+	s := d.Outline().Find(name)
+	return s, s != nil
+}
+
+// SetSection replaces the text of the first section matching name. Text
+// that does not end in a newline gets one.
+func (d *Document) SetSection(name, text string) error {
+	s, ok := d.Section(name)
+	if !ok {
+		return fmt.Errorf("section %q not found", name)
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if strings.HasSuffix(s.Text, "\n\n") && !strings.HasSuffix(text, "\n\n") {
+		text += "\n" // keep the blank line before the next heading
+	}
+	return d.replace(splice(d.content, s.start, s.end, text))
+}
@@ -0,0 +1,103 @@
+package adr
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestOutlineTemplates verifies the MADR, Nygard and bold-label layouts
+// produce outlines with canonical section names.
+func TestOutlineTemplates(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string // canonical names in document order
+	}{
+		{"madr", "title context decision-drivers considered-options decision consequences-positive consequences-negative pros-and-cons links"},
+		{"nygard", "title status date context decision consequences"},
+		{"../../examples/templates/openchami.md", "title date status participants context decision considered-options consequences non-goals points-of-contention notes links"},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.template), func(t *testing.T) {
+			m := Manager{Dir: t.TempDir()}
+			path, err := m.WriteNewADR("Use Go", NewOptions{Template: tt.template, Status: "Proposed"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := LoadDocument(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, s := range d.Sections() {
+				names = append(names, s.Name)
+			}
+			if got := strings.Join(names, " "); got != tt.want {
+				t.Errorf("section names:\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOutlineContent verifies section text, nesting, bold labels with inline
+// text, and that code blocks are skipped.
+func TestOutlineContent(t *testing.T) {
+	content := "---\nid: 1\n---\n\n# ADR 0001: Use Go\n\n" +
+		"**Status:** Accepted\n" +
+		"**Context:**\nWe need a language.\n\n" +
+		"It must be **fast** to compile.\n\n" +
+		"## Decision Outcome\nGo.\n\n```\n## Not a heading\n```\n\n" +
+		"### Positive Consequences\nSingle binary.\n\n" +
+		"## Links\n"
+	d, err := ParseDocument([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := d.Outline()
+
+	title := root.Find("title")
+	if title == nil || len(title.Children) != 4 {
+		t.Fatalf("title section should have 4 children, got %+v", title)
+	}
+	if s := root.Find("status"); s == nil || !s.Label || s.Text != "Accepted\n" || s.Line != 7 {
+		t.Errorf("status label = %+v", s)
+	}
+	if s := root.Find("Context"); s == nil || s.Text != "We need a language.\n\nIt must be **fast** to compile.\n\n" {
+		t.Errorf("context label text = %q", s.Text)
+	}
+	decision := root.Find("decision")
+	if decision == nil || decision.Title != "Decision Outcome" {
+		t.Fatalf("decision = %+v", decision)
+	}
+	if decision.Content != "Go.\n\n```\n## Not a heading\n```\n\n" {
+		t.Errorf("decision content = %q", decision.Content)
+	}
+	if len(decision.Children) != 1 || decision.Children[0].Name != "consequences-positive" {
+		t.Errorf("decision children = %+v", decision.Children)
+	}
+	if root.Find("not-a-heading") != nil {
+		t.Error("headings in code blocks should be ignored")
+	}
+
+	if err := d.SetSection("context", "Speed matters."); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(d.Bytes()), "**Context:**\nSpeed matters.\n\n## Decision Outcome\n") {
+		t.Errorf("SetSection on a label:\n%s", d.Bytes())
+	}
+}
+
+// TestSectionName checks slugs and aliases.
+func TestSectionName(t *testing.T) {
+	for in, want := range map[string]string{
+		"Context and Problem Statement": "context",
+		"Other Options Considered":      "considered-options",
+		"Decision Outcome":              "decision",
+		"  Non-Goals ":                  "non-goals",
+		"Points of Contention":          "points-of-contention",
+	} {
+		if got := SectionName(in); got != want {
+			t.Errorf("SectionName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package adr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Slug strategies.
+const (
+	SlugTransliterate = "transliterate" // ASCII, with accents and common scripts transliterated (the default)
+	SlugUnicode       = "unicode"       // letters and digits of any script are kept
+	SlugASCII         = "ascii"         // non-ASCII characters are dropped
+)
+
+// SlugConfig controls how ADR titles become file names.
+type SlugConfig struct {
+	Strategy  string `yaml:"strategy"`   // transliterate, unicode or ascii
+	MaxLength int    `yaml:"max_length"` // longest slug in bytes; default 60
+}
+
+// Validate checks the slug settings.
+func (c SlugConfig) Validate() error {
+	switch c.strategy() {
+	case SlugTransliterate, SlugUnicode, SlugASCII:
+	default:
+		return fmt.Errorf("unknown slug.strategy %q (want %s, %s or %s)", c.Strategy, SlugTransliterate, SlugUnicode, SlugASCII)
+	}
+	if c.MaxLength < 0 {
+		return fmt.Errorf("slug.max_length must not be negative")
+	}
+	return nil
+}
+
+func (c SlugConfig) strategy() string {
+	if c.Strategy == "" {
+		return SlugTransliterate
+	}
+	return strings.ToLower(c.Strategy)
+}
+
+func (c SlugConfig) maxLength() int {
+	if c.MaxLength == 0 {
+		return 60
+	}
+	return c.MaxLength
+}
+
+// Slugify turns an ADR title into the kebab-case part of its file name.
+// Spaces, slashes and underscores become dashes, other punctuation is
+// dropped, repeated dashes are collapsed and the result is cut at a word
+// boundary to the maximum length. A title that leaves nothing behind, such
+// as a Japanese title under the transliterate strategy, gets a stable hash
+// of the title instead.
+func Slugify(title string, c SlugConfig) string {
+	var b strings.Builder
+	dash := false
+	put := func(s string) {
+		if s == "-" {
+			dash = b.Len() > 0
+			return
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(s)
+	}
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			put(string(r))
+		case unicode.IsSpace(r) || r == '-' || r == '/' || r == '_':
+			put("-")
+		case r < utf8.RuneSelf:
+			// other ASCII punctuation is dropped
+		case c.strategy() == SlugUnicode && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)):
+			put(string(r))
+		case c.strategy() == SlugTransliterate:
+			if t, ok := transliterations[r]; ok {
+				put(t)
+			} else if unicode.IsPunct(r) && r != '\'' && r != '’' {
+				put("-")
+			}
+		}
+	}
+	slug := truncateSlug(b.String(), c.maxLength())
+	if slug == "" {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(title)))
+		slug = hex.EncodeToString(sum[:4])
+	}
+	return slug
+}
+
+// truncateSlug cuts slug to at most max bytes without splitting a rune,
+// preferring to end at a dash in the second half.
+func truncateSlug(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(slug[cut]) {
+		cut--
+	}
+	slug = slug[:cut]
+	if i := strings.LastIndexByte(slug, '-'); i >= max/2 {
+		slug = slug[:i]
+	}
+	return strings.Trim(slug, "-")
+}
+
+// transliterations maps lower-case letters to ASCII: Latin letters with
+// diacritics, ligatures, Cyrillic and Greek.
+var transliterations = map[rune]string{}
+
+func init() {
+	for ascii, runes := range map[string]string{
+		"a": "àáâãäåāăąǎ", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě",
+		"g": "ĝğġģ", "h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ",
+		"l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏőǒ", "r": "ŕŗř",
+		"s": "śŝşšș", "t": "ţťŧț", "u": "ùúûüũūŭůűųǔ", "w": "ŵ", "y": "ýÿŷ",
+		"z": "źżž", "ss": "ß", "ae": "æ", "oe": "œ", "th": "þ", "ij": "ĳ",
+	} {
+		for _, r := range runes {
+			transliterations[r] = ascii
+		}
+	}
+	for _, pairs := range []string{
+		// Cyrillic (Russian, Ukrainian, Belarusian, Bulgarian, Serbian)
+		"а a б b в v г g д d е e ё yo ж zh з z и i й y к k л l м m н n о o п p " +
+			"р r с s т t у u ф f х kh ц ts ч ch ш sh щ shch ы y э e ю yu я ya " +
+			"і i ї yi є ye ґ g ў u ђ dj ј j љ lj њ nj ћ c џ dz",
+		// Greek
+		"α a β v γ g δ d ε e ζ z η i θ th ι i κ k λ l μ m ν n ξ x ο o π p " +
+			"ρ r σ s ς s τ t υ y φ f χ ch ψ ps ω o ά a έ e ή i ί i ό o ύ y ώ o " +
+			"ϊ i ϋ y ΐ i ΰ y",
+	} {
+		f := strings.Fields(pairs)
+		for i := 0; i+1 < len(f); i += 2 {
+			r, _ := utf8.DecodeRuneInString(f[i])
+			transliterations[r] = f[i+1]
+		}
+	}
+	// hard and soft signs have no Latin equivalent
+	transliterations['ъ'] = ""
+	transliterations['ь'] = ""
+}
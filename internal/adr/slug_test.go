@@ -0,0 +1,40 @@
+package adr
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSlugify verifies transliteration, dash handling, truncation and the
+// hash fallback for each strategy.
+func TestSlugify(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	tests := []struct {
+		title string
+		cfg   SlugConfig
+		want  string
+	}{
+		{"Adopt DuckDB for local analytics", SlugConfig{}, "adopt-duckdb-for-local-analytics"},
+		{"Datenbank für Zählerstände", SlugConfig{}, "datenbank-fur-zahlerstande"},
+		{"Straße / Ærø — Œuvre", SlugConfig{}, "strasse-aero-oeuvre"},
+		{"Выбор базы данных", SlugConfig{}, "vybor-bazy-dannykh"},
+		{"Επιλογή βάσης", SlugConfig{}, "epilogi-vasis"},
+		{"  --Don't  panic!!  ", SlugConfig{}, "dont-panic"},
+		{"Use C++ / Go_lang", SlugConfig{}, "use-c-go-lang"},
+		{"データベースの選択", SlugConfig{}, "13e19d47"},
+		{"データベースの選択", SlugConfig{Strategy: SlugUnicode}, "データベースの選択"},
+		{"Datenbank für Zählerstände", SlugConfig{Strategy: SlugUnicode}, "datenbank-für-zählerstände"},
+		{"Datenbank für Zählerstände", SlugConfig{Strategy: SlugASCII}, "datenbank-fr-zhlerstnde"},
+		{long, SlugConfig{}, strings.TrimSuffix(strings.Repeat("word-", 12), "-")},
+		{long, SlugConfig{MaxLength: 12}, "word-word"},
+		{"ÄÄÄÄÄÄÄÄ", SlugConfig{Strategy: SlugUnicode, MaxLength: 5}, "ää"},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.title, tt.cfg); got != tt.want {
+			t.Errorf("Slugify(%q, %+v) = %q, want %q", tt.title, tt.cfg, got, tt.want)
+		}
+	}
+	if Slugify("データベースの選択", SlugConfig{}) == Slugify("別のタイトル", SlugConfig{}) {
+		t.Error("hash fallback should differ between titles")
+	}
+}
@@ -0,0 +1,150 @@
+package adr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Source is an ADR directory that contributes to a combined index.
+type Source struct {
+	Name string // namespace of the source's IDs, e.g. "billing"
+	Dir  string
+}
+
+var reSourceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// ExpandSources turns source specs into directories. A spec is a directory
+// or a glob pattern, optionally preceded by a name: "platform=ADRs". A
+// directory without a name is named after its last element; directories
+// matched by a pattern are named after the parts that matched wildcards,
+// so services/*/docs/adr yields billing for services/billing/docs/adr.
+// A name given with a pattern is prepended, as in svc/billing.
+func ExpandSources(specs []string) ([]Source, error) {
+	var out []Source
+	byName := map[string]Source{}
+	seen := map[string]bool{}
+	add := func(s Source) error {
+		if !reSourceName.MatchString(s.Name) {
+			return fmt.Errorf("source %s: invalid name %q; name it with name=%s", s.Dir, s.Name, s.Dir)
+		}
+		abs, err := filepath.Abs(s.Dir)
+		if err != nil {
+			return err
+		}
+		if seen[abs] {
+			return nil
+		}
+		seen[abs] = true
+		if other, ok := byName[s.Name]; ok {
+			return fmt.Errorf("sources %s and %s are both named %q; name one with name=dir", other.Dir, s.Dir, s.Name)
+		}
+		byName[s.Name] = s
+		out = append(out, s)
+		return nil
+	}
+
+	for _, spec := range specs {
+		name, pattern, named := strings.Cut(spec, "=")
+		if !named {
+			name, pattern = "", spec
+		}
+		pattern = filepath.Clean(strings.TrimSpace(pattern))
+		name = strings.Trim(strings.TrimSpace(name), "/")
+
+		if !hasGlobMeta(pattern) {
+			if name == "" {
+				name = filepath.Base(pattern)
+			}
+			if err := add(Source{Name: name, Dir: pattern}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", spec, err)
+		}
+		parts := strings.Split(filepath.ToSlash(pattern), "/")
+		sort.Strings(matches)
+		for _, match := range matches {
+			if fi, err := os.Stat(match); err != nil || !fi.IsDir() {
+				continue
+			}
+			var wild []string
+			if name != "" {
+				wild = append(wild, name)
+			}
+			for i, part := range strings.Split(filepath.ToSlash(match), "/") {
+				if i < len(parts) && hasGlobMeta(parts[i]) {
+					wild = append(wild, part)
+				}
+			}
+			if err := add(Source{Name: strings.Join(wild, "/"), Dir: match}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[`)
+}
+
+// ScanSources reads the ADRs of every source and tags each entry with the
+// name of its source. When there is more than one source, IDs and the
+// references between ADRs are namespaced with the source name, as in
+// billing:0003, so numbers from different sources cannot collide. Entries
+// are ordered by source, then as Scan orders them.
+func ScanSources(sources []Source, scheme IDScheme) ([]Entry, error) {
+	var out []Entry
+	for _, s := range sources {
+		entries, err := scanDir(s.Dir, scheme)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", s.Name, err)
+		}
+		for _, e := range entries {
+			e.Source = s.Name
+			if len(sources) > 1 {
+				e.ID = s.Name + ":" + e.ID
+				e.Relations = e.Relations.qualify(s.Name)
+			}
+			out = append(out, e)
+		}
+	}
+	if out == nil {
+		out = []Entry{}
+	}
+	return out, nil
+}
+
+// qualify prefixes the references that do not name a source with ns.
+func (r Relations) qualify(ns string) Relations {
+	q := func(ids IDList) IDList {
+		if ids == nil {
+			return nil
+		}
+		out := make(IDList, len(ids))
+		for i, id := range ids {
+			if strings.Contains(id, ":") {
+				out[i] = id
+			} else {
+				out[i] = ns + ":" + id
+			}
+		}
+		return out
+	}
+	return Relations{
+		Supersedes:   q(r.Supersedes),
+		SupersededBy: q(r.SupersededBy),
+		Amends:       q(r.Amends),
+		AmendedBy:    q(r.AmendedBy),
+		DependsOn:    q(r.DependsOn),
+		RelatesTo:    q(r.RelatesTo),
+	}
+}
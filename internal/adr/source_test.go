@@ -0,0 +1,123 @@
+package adr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExpandSources verifies that plain directories are named after their
+// last element, glob matches after the wildcard parts, and that explicit
+// names and name clashes are handled.
+func TestExpandSources(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"ADRs", "services/billing/docs/adr", "services/auth/docs/adr", "services/web/docs"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := func(p string) string { return filepath.Join(root, filepath.FromSlash(p)) }
+
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []string // name=dir, relative to root
+		wantErr string
+	}{
+		{"directory", []string{at("ADRs")}, []string{"ADRs=ADRs"}, ""},
+		{"glob", []string{at("services/*/docs/adr")}, []string{"auth=services/auth/docs/adr", "billing=services/billing/docs/adr"}, ""},
+		{"named", []string{"top=" + at("ADRs"), "svc=" + at("services/*/docs/adr")}, []string{"top=ADRs", "svc/auth=services/auth/docs/adr", "svc/billing=services/billing/docs/adr"}, ""},
+		{"duplicate dir", []string{at("ADRs"), at("ADRs/")}, []string{"ADRs=ADRs"}, ""},
+		{"clash", []string{at("services/auth/docs/adr"), at("services/billing/docs/adr")}, nil, `both named "adr"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandSources(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, s := range got {
+				rel, _ := filepath.Rel(root, s.Dir)
+				names = append(names, s.Name+"="+filepath.ToSlash(rel))
+			}
+			if strings.Join(names, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+// TestScanSources verifies that entries from several sources get
+// namespaced IDs and references, and that the index groups them by source
+// with links relative to the output file.
+func TestScanSources(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ADRs/0001-use-go.md", "---\nid: 1\ntitle: Use Go\nstatus: Accepted\n---\n")
+	write("services/billing/docs/adr/0001-use-stripe.md", "---\nid: 1\ntitle: Use Stripe\nstatus: Superseded\nsuperseded_by: 2\n---\n")
+	write("services/billing/docs/adr/0002-use-adyen.md", "---\nid: 2\ntitle: Use Adyen\nstatus: Accepted\nsupersedes: 1\ndepends_on: [\"ADRs:1\"]\n---\n")
+
+	sources, err := ExpandSources([]string{filepath.Join(root, "ADRs"), filepath.Join(root, "services/*/docs/adr")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ScanSources(sources, IDScheme{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	if got := strings.Join(ids, " "); got != "ADRs:0001 billing:0001 billing:0002" {
+		t.Fatalf("ids: got %s", got)
+	}
+	adyen := entries[2]
+	if adyen.Source != "billing" || adyen.Supersedes[0] != "billing:1" || adyen.DependsOn[0] != "ADRs:1" {
+		t.Errorf("adyen: got source %q, supersedes %v, depends on %v", adyen.Source, adyen.Supersedes, adyen.DependsOn)
+	}
+	if e, ok := Lookup(entries, "billing:1"); !ok || e.Title != "Use Stripe" {
+		t.Errorf("Lookup(billing:1): got %+v, %v", e, ok)
+	}
+
+	out := filepath.Join(root, "docs", "index.md")
+	opt := IndexOptions{SourceLinks: map[string]string{}}
+	for _, s := range sources {
+		opt.SourceLinks[s.Name] = LinkBase(out, s.Dir)
+	}
+	var buf bytes.Buffer
+	if err := RenderIndex(&buf, entries, opt); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"### ADRs\n",
+		"### billing\n",
+		"[Use Go](../ADRs/0001-use-go.md)",
+		"[Use Adyen](../services/billing/docs/adr/0002-use-adyen.md) (supersedes [billing:0001](../services/billing/docs/adr/0001-use-stripe.md))",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("index is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "### ADRs") > strings.Index(got, "### billing") {
+		t.Errorf("groups should follow the source order:\n%s", got)
+	}
+}
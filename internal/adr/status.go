@@ -0,0 +1,213 @@
+package adr
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StatusOptions controls SetStatus.
+type StatusOptions struct {
+	Date    string // also set the ADR date (ISO); empty leaves it unchanged
+	History bool   // append the change to status_history in frontmatter
+	Force   bool   // skip the lifecycle transition check
+}
+
+// SetStatus changes the status of the ADR with the given id, checking the
+// transition against the lifecycle. The status is rewritten everywhere
+// ParseADR looks for it: frontmatter, **Status:** and "- Status:" lines, and
+// the first line of a "## Status" section. It returns the updated entry.
+func (m Manager) SetStatus(id, status string, opt StatusOptions) (Entry, error) {
+	e, err := m.Find(id)
+	if err != nil {
+		return Entry{}, err
+	}
+	canonical, ok := m.Config.Lifecycle.Normalize(status)
+	if !ok {
+		return Entry{}, m.Config.Lifecycle.unknown(status)
+	}
+	if !opt.Force {
+		if err := m.Config.Lifecycle.CheckTransition(e.Status, canonical); err != nil {
+			return Entry{}, fmt.Errorf("ADR %s: %w", e.ID, err)
+		}
+	}
+	if err := m.writeStatus(e, canonical, opt); err != nil {
+		return Entry{}, err
+	}
+	e.Status = canonical
+	if opt.Date != "" {
+		e.Date = opt.Date
+	}
+	return e, nil
+}
+
+// writeStatus rewrites the status (and optionally date and history) of e
+// without any lifecycle checks.
+func (m Manager) writeStatus(e Entry, status string, opt StatusOptions) error {
+	d, err := LoadDocument(filepath.Join(m.Dir, e.File))
+	if err != nil {
+		return err
+	}
+	if err := rewriteStatus(d, status); err != nil {
+		return err
+	}
+	if opt.Date != "" {
+		if err := rewriteDate(d, opt.Date); err != nil {
+			return err
+		}
+	}
+	if opt.History && d.HasFrontmatter() {
+		date := opt.Date
+		if date == "" {
+			date = time.Now().Format("2006-01-02")
+		}
+		if err := appendHistory(d, e.Status, status, date); err != nil {
+			return err
+		}
+	}
+	return d.Save()
+}
+
+var (
+	reStatusValue = regexp.MustCompile(`(?i)^(\*\*Status:\*\*|[-*]\s*Status:?|\s*Status:)\s*`)
+	reDateHeading = regexp.MustCompile(`(?i)^##\s*Date\s*$`)
+	reDateValue   = regexp.MustCompile(`[0-9]{4}-[0-9]{2}-[0-9]{2}`)
+)
+
+// bodyLines splits the body into lines and returns them with their text
+// as markdown; see markdownLines.
+func bodyLines(d *Document) ([]string, []string) {
+	lines := strings.SplitAfter(string(d.Body()), "\n")
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i], _ = splitEOL(line)
+	}
+	return lines, markdownLines(d.markup, texts)
+}
+
+// rewriteStatus replaces the status in the frontmatter and in the first
+// status line and "## Status" section of the body. Files without
+// frontmatter do not gain one.
+func rewriteStatus(d *Document, status string) error {
+	if d.HasFrontmatter() {
+		if err := d.Set("status", status); err != nil {
+			return err
+		}
+	}
+	lines, md := bodyLines(d)
+
+	doneKV, doneSection := false, false
+	for i := 0; i < len(lines); i++ {
+		text, eol := splitEOL(lines[i])
+		if strings.HasPrefix(md[i], "##") && !reStatus.MatchString(md[i]) {
+			// "Status:" lines are metadata near the top; past the first
+			// other section they are prose.
+			doneKV = true
+		}
+		if !doneKV {
+			if loc := reStatusValue.FindStringIndex(text); loc != nil && loc[1] < len(text) {
+				lines[i] = text[:loc[1]] + status + eol
+				doneKV = true
+				continue
+			}
+		}
+		if !doneSection && reStatus.MatchString(md[i]) {
+			doneSection = true
+			j := i + 1
+			for j < len(lines) && strings.TrimSpace(md[j]) == "" {
+				j++
+			}
+			if j < len(lines) && reStatusValue.MatchString(lines[j]) {
+				continue // a "Status:" line, rewritten above
+			}
+			if j < len(lines) && !strings.HasPrefix(strings.TrimSpace(md[j]), "#") {
+				_, jeol := splitEOL(lines[j])
+				lines[j] = status + jeol
+				i = j
+				continue
+			}
+			// empty section: add the status right below the heading
+			i = headingEnd(d.markup, lines, i)
+			text, eol = splitEOL(lines[i])
+			if eol == "" {
+				eol = "\n"
+			}
+			lines[i] = text + eol + status + eol
+		}
+	}
+	return d.SetBody([]byte(strings.Join(lines, "")))
+}
+
+// rewriteDate replaces the date in the frontmatter, the first "Date:",
+// "- Date:" or "**Date:**" line and the first line of a "## Date" section
+// of the body.
+func rewriteDate(d *Document, date string) error {
+	if d.HasFrontmatter() {
+		if err := d.Set("date", date); err != nil {
+			return err
+		}
+	}
+	lines, md := bodyLines(d)
+
+	doneKV, doneSection := false, false
+	for i := 0; i < len(lines); i++ {
+		text, eol := splitEOL(lines[i])
+		if strings.HasPrefix(md[i], "##") && !reDateHeading.MatchString(md[i]) {
+			// like "Status:" lines, "Date:" lines past the first other
+			// section are prose
+			doneKV = true
+		}
+		if !doneKV && reDateKV.MatchString(text) {
+			lines[i] = reDateValue.ReplaceAllLiteralString(text, date) + eol
+			doneKV = true
+			continue
+		}
+		if !doneSection && reDateHeading.MatchString(md[i]) {
+			doneSection = true
+			for j := i + 1; j < len(lines); j++ {
+				if strings.TrimSpace(md[j]) == "" {
+					continue
+				}
+				if t, jeol := splitEOL(lines[j]); reDateValue.MatchString(t) {
+					lines[j] = reDateValue.ReplaceAllLiteralString(t, date) + jeol
+				}
+				i = j
+				break
+			}
+		}
+	}
+	return d.SetBody([]byte(strings.Join(lines, "")))
+}
+
+// splitEOL splits a line from strings.SplitAfter into its text and line
+// ending.
+func splitEOL(line string) (string, string) {
+	text := strings.TrimRight(line, "\r\n")
+	return text, line[len(text):]
+}
+
+// appendHistory adds a {status, date, from} entry to the status_history
+// list in the frontmatter, creating the list if needed.
+func appendHistory(d *Document, from, to, date string) error {
+	list := d.Node("status_history")
+	if list == nil || list.Kind != yaml.SequenceNode {
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+	}
+	entry := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+	add := func(k, v string) {
+		entry.Content = append(entry.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: k},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: v, Style: yaml.DoubleQuotedStyle})
+	}
+	add("status", to)
+	add("date", date)
+	if from != "" {
+		add("from", from)
+	}
+	list.Content = append(list.Content, entry)
+	return d.SetNode("status_history", list)
+}
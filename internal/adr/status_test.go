@@ -0,0 +1,141 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRewriteStatus verifies every status location recognized by ParseADR
+// is updated and nothing else changes.
+func TestRewriteStatus(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "madr",
+			in:   "---\nid: 1\nstatus: \"Proposed\"\n---\n\n# ADR 0001: X\n\n- Status: Proposed\n- Date: 2025-01-15\n",
+			want: "---\nid: 1\nstatus: \"Accepted\"\n---\n\n# ADR 0001: X\n\n- Status: Accepted\n- Date: 2025-01-15\n",
+		},
+		{
+			name: "nygard",
+			in:   "---\nstatus: \"Proposed\"\n---\n\n## Status\nProposed\n\n## Context\nStatus: is not a status line here\n",
+			want: "---\nstatus: \"Accepted\"\n---\n\n## Status\nAccepted\n\n## Context\nStatus: is not a status line here\n",
+		},
+		{
+			name: "bold without frontmatter",
+			in:   "# ADR 0002: Y\r\n\r\n**Status:** Proposed\r\n",
+			want: "# ADR 0002: Y\r\n\r\n**Status:** Accepted\r\n",
+		},
+		{
+			name: "empty status section",
+			in:   "# ADR 0003: Z\n\n## Status\n\n## Context\n",
+			want: "# ADR 0003: Z\n\n## Status\nAccepted\n\n## Context\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDocument([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rewriteStatus(d, "Accepted"); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(d.Bytes()); got != tt.want {
+				t.Errorf("rewriteStatus:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRewriteDate verifies the MADR date bullet and the other date lines
+// recognized by ParseADR are updated.
+func TestRewriteDate(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "madr",
+			in:   "---\nid: 1\ndate: \"2025-01-15\"\n---\n\n# ADR 0001: X\n\n- Status: Proposed\n- Date: 2025-01-15\n\n## Context\n\n- Date: 2024-12-01 is not a date line here\n",
+			want: "---\nid: 1\ndate: \"2025-02-01\"\n---\n\n# ADR 0001: X\n\n- Status: Proposed\n- Date: 2025-02-01\n\n## Context\n\n- Date: 2024-12-01 is not a date line here\n",
+		},
+		{
+			name: "plain frontmatter date",
+			in:   "---\nid: 1\ndate: 2025-01-15\n---\n\n# ADR 0001: X\n",
+			want: "---\nid: 1\ndate: 2025-02-01\n---\n\n# ADR 0001: X\n",
+		},
+		{
+			name: "bold without frontmatter",
+			in:   "# ADR 0002: Y\n\n**Date:** 2025-01-15\n",
+			want: "# ADR 0002: Y\n\n**Date:** 2025-02-01\n",
+		},
+		{
+			name: "date section",
+			in:   "# ADR 0003: Z\n\n## Date\n\n2025-01-15\n",
+			want: "# ADR 0003: Z\n\n## Date\n\n2025-02-01\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDocument([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rewriteDate(d, "2025-02-01"); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(d.Bytes()); got != tt.want {
+				t.Errorf("rewriteDate:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetStatus verifies the lifecycle check, date update and status
+// history, and that the result parses back.
+func TestSetStatus(t *testing.T) {
+	dir := t.TempDir()
+	m := Manager{Dir: dir}
+	path, err := m.WriteNewADR("Use Go", NewOptions{Template: "nygard", Date: "2025-01-15"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.SetStatus("1", "Deprecated", StatusOptions{}); err == nil {
+		t.Error("Proposed -> Deprecated should be rejected by the default lifecycle")
+	}
+	e, err := m.SetStatus("1", "approved", StatusOptions{Date: "2025-02-01", History: true})
+	if err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	if e.Status != "Accepted" {
+		t.Errorf("returned status %q, want Accepted", e.Status)
+	}
+	if _, err := m.SetStatus("1", "Deprecated", StatusOptions{History: true, Date: "2025-03-01"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	for _, want := range []string{
+		"status: \"Deprecated\"\n",
+		"date: \"2025-03-01\"\n",
+		"status_history:\n" +
+			"  - {status: \"Accepted\", date: \"2025-02-01\", from: \"Proposed\"}\n" +
+			"  - {status: \"Deprecated\", date: \"2025-03-01\", from: \"Accepted\"}\n---\n",
+		"## Status\nDeprecated\n",
+		"## Date\n2025-03-01\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("ADR missing %q:\n%s", want, content)
+		}
+	}
+	if meta, err := ParseADR(filepath.Join(dir, "0001-use-go.md")); err != nil || meta.Status != "Deprecated" {
+		t.Errorf("ParseADR status = %q, err %v", meta.Status, err)
+	}
+
+	if _, err := m.SetStatus("1", "Proposed", StatusOptions{Force: true}); err != nil {
+		t.Errorf("--force should bypass the lifecycle: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package adr
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Supersede creates a new ADR titled title that supersedes oldID, and marks
+// the old ADR as superseded by it. It returns the path of the new ADR.
+func (m Manager) Supersede(oldID, title string, opt NewOptions) (string, error) {
+	old, err := m.Find(oldID)
+	if err != nil {
+		return "", err
+	}
+	if err := m.Config.Lifecycle.CheckTransition(old.Status, "Superseded"); err != nil {
+		return "", fmt.Errorf("ADR %s: %w", old.ID, err)
+	}
+	opt.Supersedes = IDList{old.ID}
+	path, newID, err := m.writeNewADR(title, opt)
+	if err != nil {
+		return "", err
+	}
+	if err := m.markSuperseded(old, newID); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// SupersedeBy records that the existing ADR newID supersedes oldID, linking
+// the two records in both directions.
+func (m Manager) SupersedeBy(oldID, newID string) error {
+	entries, err := m.Scan()
+	if err != nil {
+		return err
+	}
+	old, ok := Lookup(entries, oldID)
+	if !ok {
+		return fmt.Errorf("ADR %s not found in %s", oldID, m.Dir)
+	}
+	repl, ok := Lookup(entries, newID)
+	if !ok {
+		return fmt.Errorf("ADR %s not found in %s", newID, m.Dir)
+	}
+	if old.File == repl.File {
+		return fmt.Errorf("ADR %s cannot supersede itself", old.ID)
+	}
+	if err := m.Config.Lifecycle.CheckTransition(old.Status, "Superseded"); err != nil {
+		return fmt.Errorf("ADR %s: %w", old.ID, err)
+	}
+
+	if !repl.Supersedes.Contains(old.ID) {
+		ids := append(repl.Supersedes, old.ID)
+		if err := m.editFrontmatter(repl, "supersedes", ids); err != nil {
+			return err
+		}
+	}
+	return m.markSuperseded(old, repl.ID)
+}
+
+// Find returns the entry for the ADR with the given id.
+func (m Manager) Find(id string) (Entry, error) {
+	entries, err := m.Scan()
+	if err != nil {
+		return Entry{}, err
+	}
+	e, ok := Lookup(entries, id)
+	if !ok {
+		return Entry{}, fmt.Errorf("ADR %s not found in %s", id, m.Dir)
+	}
+	return e, nil
+}
+
+func (m Manager) markSuperseded(old Entry, newID string) error {
+	if err := m.writeStatus(old, "Superseded by ADR "+newID, StatusOptions{}); err != nil {
+		return err
+	}
+	d, err := LoadDocument(filepath.Join(m.Dir, old.File))
+	if err != nil {
+		return err
+	}
+	if !d.HasFrontmatter() {
+		// Legacy ADRs keep their format; ParseADR reads the link back from
+		// the status line.
+		return nil
+	}
+
+	by := old.SupersededBy
+	if !by.Contains(newID) {
+		by = append(by, newID)
+	}
+	if err := d.Set("superseded_by", by); err != nil {
+		return err
+	}
+	return d.Save()
+}
+
+// editFrontmatter sets a single frontmatter field of an ADR in place.
+func (m Manager) editFrontmatter(e Entry, key string, value any) error {
+	d, err := LoadDocument(filepath.Join(m.Dir, e.File))
+	if err != nil {
+		return err
+	}
+	if err := d.Set(key, value); err != nil {
+		return err
+	}
+	return d.Save()
+}
@@ -0,0 +1,138 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSupersedeCreatesLinkedADR verifies that superseding with a new title
+// links both records and preserves the old ADR's other content.
+func TestSupersedeCreatesLinkedADR(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := Manager{Dir: tmpDir}
+
+	oldContent := `---
+id: 0001
+title: "Use MySQL"
+status: "Accepted"
+date: "2025-01-15"
+deciders: [alice, bob]
+---
+
+# ADR 0001: Use MySQL
+
+We chose MySQL.
+`
+	oldPath := filepath.Join(tmpDir, "0001-use-mysql.md")
+	if err := os.WriteFile(oldPath, []byte(oldContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPath, err := m.Supersede("1", "Use PostgreSQL", NewOptions{Date: "2025-02-01"})
+	if err != nil {
+		t.Fatalf("Supersede failed: %v", err)
+	}
+	if newPath != filepath.Join(tmpDir, "0002-use-postgresql.md") {
+		t.Errorf("unexpected new path %s", newPath)
+	}
+
+	newMeta, err := ParseADR(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !newMeta.Supersedes.Contains("1") {
+		t.Errorf("new ADR should supersede 0001, got %v", newMeta.Supersedes)
+	}
+
+	oldMeta, err := ParseADR(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oldMeta.Status != "Superseded by ADR 0002" {
+		t.Errorf("old status: got %q", oldMeta.Status)
+	}
+	if !oldMeta.SupersededBy.Contains("0002") {
+		t.Errorf("old ADR should be superseded by 0002, got %v", oldMeta.SupersededBy)
+	}
+
+	updated, err := os.ReadFile(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keep := range []string{"deciders: [alice, bob]", "# ADR 0001: Use MySQL\n\nWe chose MySQL.\n", `title: "Use MySQL"`} {
+		if !strings.Contains(string(updated), keep) {
+			t.Errorf("old ADR lost content %q:\n%s", keep, updated)
+		}
+	}
+}
+
+// TestSupersedeByExisting links two existing ADRs, including a legacy ADR
+// without frontmatter.
+func TestSupersedeByExisting(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := Manager{Dir: tmpDir}
+
+	legacy := "# ADR 0001: Legacy Decision\n\n## Status\nAccepted\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "0001-legacy-decision.md"), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteNewADR("Replacement", NewOptions{Date: "2025-01-20"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SupersedeBy("0001", "2"); err != nil {
+		t.Fatalf("SupersedeBy failed: %v", err)
+	}
+	// Linking twice must not duplicate references.
+	if err := m.SupersedeBy("0001", "2"); err != nil {
+		t.Fatalf("second SupersedeBy failed: %v", err)
+	}
+
+	// The legacy ADR keeps its format: only the status line changes.
+	updated, err := os.ReadFile(filepath.Join(tmpDir, "0001-legacy-decision.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# ADR 0001: Legacy Decision\n\n## Status\nSuperseded by ADR 0002\n"; string(updated) != want {
+		t.Errorf("legacy ADR rewritten:\ngot  %q\nwant %q", updated, want)
+	}
+
+	entries, err := Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	old, repl := entries[0], entries[1]
+	if old.Title != "Legacy Decision" {
+		t.Errorf("legacy title: got %q", old.Title)
+	}
+	if old.Status != "Superseded by ADR 0002" {
+		t.Errorf("legacy status: got %q", old.Status)
+	}
+	if len(old.SupersededBy) != 1 || len(repl.Supersedes) != 1 {
+		t.Errorf("expected single links, got superseded_by=%v supersedes=%v", old.SupersededBy, repl.Supersedes)
+	}
+
+	if err := m.SupersedeBy("2", "2"); err == nil {
+		t.Error("expected error when an ADR supersedes itself")
+	}
+	if err := m.SupersedeBy("9", "2"); err == nil {
+		t.Error("expected error for unknown ADR")
+	}
+
+	indexPath := filepath.Join(tmpDir, "index.md")
+	if err := WriteIndex(indexPath, entries, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "(superseded by [0002](./0002-replacement.md))") {
+		t.Errorf("index should show the supersession chain:\n%s", index)
+	}
+}
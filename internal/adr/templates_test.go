@@ -206,7 +206,9 @@ Additional notes.
 // TestTemplateVariables ensures all template variables work
 func TestTemplateVariables(t *testing.T) {
 	tmpDir := t.TempDir()
-	m := Manager{Dir: tmpDir}
+	// Custom statuses must be declared in the lifecycle
+	lifecycle := Lifecycle{Statuses: []StatusDef{{Name: "Experimental"}}}
+	m := Manager{Dir: tmpDir, Config: Config{Lifecycle: lifecycle}}
 
 	title := "Variable Test Decision"
 	status := "Experimental"  